@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminController handles administrator requests
+type AdminController struct {
+	imageUseCase domain.ImageUseCase
+}
+
+// NewAdminController creates a new admin controller
+func NewAdminController(imageUseCase domain.ImageUseCase) *AdminController {
+	return &AdminController{
+		imageUseCase: imageUseCase,
+	}
+}
+
+// GetDuplicateFlags handles listing images flagged as possible reposts
+func (c *AdminController) GetDuplicateFlags(ctx echo.Context) error {
+	page, limit := getPaginationParams(ctx)
+	flags, err := c.imageUseCase.GetDuplicateFlags(ctx.QueryParam("status"), page, limit)
+	if err != nil {
+		if err == domain.ErrInvalidInput {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid status",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get duplicate flags",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, flags)
+}
+
+// ReviewDuplicateFlag handles resolving a duplicate flag
+func (c *AdminController) ReviewDuplicateFlag(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	flagIDStr := ctx.Param("id")
+	flagID, err := strconv.ParseUint(flagIDStr, 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid flag ID",
+		})
+	}
+
+	var req struct {
+		Status string `json:"status"` // confirmed or dismissed
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	flag, err := c.imageUseCase.ReviewDuplicateFlag(userID, uint(flagID), req.Status)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Status must be confirmed or dismissed",
+			})
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Duplicate flag not found",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to review duplicate flag",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, flag)
+}
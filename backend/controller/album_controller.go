@@ -0,0 +1,325 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AlbumController handles album requests
+type AlbumController struct {
+	albumUseCase domain.AlbumUseCase
+}
+
+// NewAlbumController creates a new album controller
+func NewAlbumController(albumUseCase domain.AlbumUseCase) *AlbumController {
+	return &AlbumController{
+		albumUseCase: albumUseCase,
+	}
+}
+
+// CreateAlbum handles album creation
+func (c *AlbumController) CreateAlbum(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Visibility  string `json:"visibility"`
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	album, err := c.albumUseCase.CreateAlbum(userID, req.Title, req.Description, req.Visibility)
+	if err != nil {
+		if err == domain.ErrInvalidInput {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Title is required and visibility must be public, members, unlisted or private",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to create album",
+		})
+	}
+
+	return ctx.JSON(http.StatusCreated, album)
+}
+
+// GetAlbum handles getting a single album
+func (c *AlbumController) GetAlbum(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	album, err := c.albumUseCase.GetAlbum(viewerID, uint(albumID))
+	if err != nil {
+		return albumErrorResponse(ctx, err, "Failed to get album")
+	}
+
+	return ctx.JSON(http.StatusOK, album)
+}
+
+// GetMyAlbums handles getting the user's albums
+func (c *AlbumController) GetMyAlbums(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	page, limit := getPaginationParams(ctx)
+	albums, err := c.albumUseCase.GetMyAlbums(userID, page, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get albums",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, albums)
+}
+
+// GetUserAlbums handles the public album list of a user
+func (c *AlbumController) GetUserAlbums(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
+	page, limit := getPaginationParams(ctx)
+	albums, err := c.albumUseCase.GetUserAlbums(viewerID, ctx.Param("username"), page, limit)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "User not found",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get albums",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, albums)
+}
+
+// GetUserAlbum handles the public page of a user's album
+func (c *AlbumController) GetUserAlbum(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	album, err := c.albumUseCase.GetUserAlbum(viewerID, ctx.Param("username"), uint(albumID))
+	if err != nil {
+		return albumErrorResponse(ctx, err, "Failed to get album")
+	}
+
+	return ctx.JSON(http.StatusOK, album)
+}
+
+// UpdateAlbum handles updating an album's title, description, visibility and cover
+func (c *AlbumController) UpdateAlbum(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	var req struct {
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		Visibility   string `json:"visibility"`     // empty keeps the current visibility
+		CoverImageID *uint  `json:"cover_image_id"` // null falls back to the first image
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	album, err := c.albumUseCase.UpdateAlbum(userID, uint(albumID), req.Title, req.Description, req.Visibility, req.CoverImageID)
+	if err != nil {
+		return albumErrorResponse(ctx, err, "Failed to update album")
+	}
+
+	return ctx.JSON(http.StatusOK, album)
+}
+
+// DeleteAlbum handles deleting an album
+func (c *AlbumController) DeleteAlbum(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	err = c.albumUseCase.DeleteAlbum(userID, uint(albumID))
+	if err != nil {
+		return albumErrorResponse(ctx, err, "Failed to delete album")
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Album deleted successfully",
+	})
+}
+
+// AddAlbumItem handles adding an image or post to an album
+func (c *AlbumController) AddAlbumItem(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	var req struct {
+		ItemType string `json:"item_type"` // image or post
+		ItemID   uint   `json:"item_id"`
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	album, err := c.albumUseCase.AddAlbumItem(userID, uint(albumID), req.ItemType, req.ItemID)
+	if err != nil {
+		if err == domain.ErrConflict {
+			return ctx.JSON(http.StatusConflict, map[string]string{
+				"error": "The item is already in the album",
+			})
+		}
+		return albumErrorResponse(ctx, err, "Failed to add item to album")
+	}
+
+	return ctx.JSON(http.StatusOK, album)
+}
+
+// RemoveAlbumItem handles removing an item from an album
+func (c *AlbumController) RemoveAlbumItem(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	itemID, err := strconv.ParseUint(ctx.Param("itemId"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid item ID",
+		})
+	}
+
+	album, err := c.albumUseCase.RemoveAlbumItem(userID, uint(albumID), uint(itemID))
+	if err != nil {
+		return albumErrorResponse(ctx, err, "Failed to remove item from album")
+	}
+
+	return ctx.JSON(http.StatusOK, album)
+}
+
+// ReorderAlbumItems handles reordering the items of an album
+func (c *AlbumController) ReorderAlbumItems(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	albumID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album ID",
+		})
+	}
+
+	var req struct {
+		ItemIDs []uint `json:"item_ids"` // every item of the album, in the new order
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	album, err := c.albumUseCase.ReorderAlbumItems(userID, uint(albumID), req.ItemIDs)
+	if err != nil {
+		return albumErrorResponse(ctx, err, "Failed to reorder album items")
+	}
+
+	return ctx.JSON(http.StatusOK, album)
+}
+
+// albumErrorResponse maps album errors to responses
+func albumErrorResponse(ctx echo.Context, err error, message string) error {
+	switch err {
+	case domain.ErrInvalidInput:
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid album request",
+		})
+	case domain.ErrNotFound:
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "Album or item not found",
+		})
+	case domain.ErrForbidden:
+		return ctx.JSON(http.StatusForbidden, map[string]string{
+			"error": "You don't have permission to modify this album",
+		})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": message,
+		})
+	}
+}
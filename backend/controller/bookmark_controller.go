@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BookmarkController handles bookmark requests
+type BookmarkController struct {
+	bookmarkUseCase domain.BookmarkUseCase
+}
+
+// NewBookmarkController creates a new bookmark controller
+func NewBookmarkController(bookmarkUseCase domain.BookmarkUseCase) *BookmarkController {
+	return &BookmarkController{
+		bookmarkUseCase: bookmarkUseCase,
+	}
+}
+
+// AddBookmark handles bookmarking an image or post, or moving an existing bookmark to another folder
+func (c *BookmarkController) AddBookmark(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req struct {
+		ItemType string `json:"item_type"` // image or post
+		ItemID   uint   `json:"item_id"`
+		FolderID *uint  `json:"folder_id"` // null or omitted for no folder
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	bookmark, err := c.bookmarkUseCase.AddBookmark(userID, req.ItemType, req.ItemID, req.FolderID)
+	if err != nil {
+		return bookmarkErrorResponse(ctx, err, "Failed to add bookmark")
+	}
+
+	return ctx.JSON(http.StatusCreated, bookmark)
+}
+
+// RemoveBookmark handles removing a bookmark
+func (c *BookmarkController) RemoveBookmark(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	itemID, err := strconv.ParseUint(ctx.QueryParam("item_id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid item ID",
+		})
+	}
+
+	err = c.bookmarkUseCase.RemoveBookmark(userID, ctx.QueryParam("item_type"), uint(itemID))
+	if err != nil {
+		return bookmarkErrorResponse(ctx, err, "Failed to remove bookmark")
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Bookmark removed successfully",
+	})
+}
+
+// GetBookmarks handles getting the user's bookmarks, optionally limited to one folder
+func (c *BookmarkController) GetBookmarks(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var folderID *uint
+	if folderIDStr := ctx.QueryParam("folder_id"); folderIDStr != "" {
+		id, err := strconv.ParseUint(folderIDStr, 10, 32)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid folder ID",
+			})
+		}
+		folder := uint(id)
+		folderID = &folder
+	}
+
+	page, limit := getPaginationParams(ctx)
+	bookmarks, err := c.bookmarkUseCase.GetBookmarks(userID, folderID, page, limit)
+	if err != nil {
+		return bookmarkErrorResponse(ctx, err, "Failed to get bookmarks")
+	}
+
+	return ctx.JSON(http.StatusOK, bookmarks)
+}
+
+// GetFolders handles getting the user's bookmark folders
+func (c *BookmarkController) GetFolders(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	folders, err := c.bookmarkUseCase.GetFolders(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get bookmark folders",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, folders)
+}
+
+// CreateFolder handles creating a bookmark folder
+func (c *BookmarkController) CreateFolder(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	folder, err := c.bookmarkUseCase.CreateFolder(userID, req.Name)
+	if err != nil {
+		return bookmarkErrorResponse(ctx, err, "Failed to create bookmark folder")
+	}
+
+	return ctx.JSON(http.StatusCreated, folder)
+}
+
+// RenameFolder handles renaming a bookmark folder
+func (c *BookmarkController) RenameFolder(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	folderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid folder ID",
+		})
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	folder, err := c.bookmarkUseCase.RenameFolder(userID, uint(folderID), req.Name)
+	if err != nil {
+		return bookmarkErrorResponse(ctx, err, "Failed to rename bookmark folder")
+	}
+
+	return ctx.JSON(http.StatusOK, folder)
+}
+
+// DeleteFolder handles deleting a bookmark folder; its bookmarks are kept without a folder
+func (c *BookmarkController) DeleteFolder(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	folderID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid folder ID",
+		})
+	}
+
+	err = c.bookmarkUseCase.DeleteFolder(userID, uint(folderID))
+	if err != nil {
+		return bookmarkErrorResponse(ctx, err, "Failed to delete bookmark folder")
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Bookmark folder deleted successfully",
+	})
+}
+
+// bookmarkErrorResponse maps bookmark use case errors to responses
+func bookmarkErrorResponse(ctx echo.Context, err error, message string) error {
+	switch err {
+	case domain.ErrInvalidInput:
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Item type must be image or post and folder names must be 1-50 characters",
+		})
+	case domain.ErrNotFound:
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "Item, bookmark or folder not found",
+		})
+	case domain.ErrConflict:
+		return ctx.JSON(http.StatusConflict, map[string]string{
+			"error": "A bookmark folder with this name already exists",
+		})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": message,
+		})
+	}
+}
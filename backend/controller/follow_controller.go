@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FollowController handles follow and feed requests
+type FollowController struct {
+	followUseCase domain.FollowUseCase
+}
+
+// NewFollowController creates a new follow controller
+func NewFollowController(followUseCase domain.FollowUseCase) *FollowController {
+	return &FollowController{
+		followUseCase: followUseCase,
+	}
+}
+
+// Follow handles following a user
+func (c *FollowController) Follow(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	err = c.followUseCase.Follow(userID, ctx.Param("username"))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "User not found",
+			})
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "You cannot follow yourself",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to follow user",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "User followed successfully",
+	})
+}
+
+// Unfollow handles unfollowing a user
+func (c *FollowController) Unfollow(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	err = c.followUseCase.Unfollow(userID, ctx.Param("username"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "User not found or not followed",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to unfollow user",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "User unfollowed successfully",
+	})
+}
+
+// GetFollowers handles the follower list of a user
+func (c *FollowController) GetFollowers(ctx echo.Context) error {
+	page, limit := getPaginationParams(ctx)
+	users, err := c.followUseCase.GetFollowers(ctx.Param("username"), page, limit)
+	if err != nil {
+		return followListErrorResponse(ctx, err, "Failed to get followers")
+	}
+
+	return ctx.JSON(http.StatusOK, users)
+}
+
+// GetFollowing handles the list of users a user follows
+func (c *FollowController) GetFollowing(ctx echo.Context) error {
+	page, limit := getPaginationParams(ctx)
+	users, err := c.followUseCase.GetFollowing(ctx.Param("username"), page, limit)
+	if err != nil {
+		return followListErrorResponse(ctx, err, "Failed to get followed users")
+	}
+
+	return ctx.JSON(http.StatusOK, users)
+}
+
+// GetFeed handles the feed of recent posts from followed users.
+// Pass the returned next_cursor as ?cursor= to get the following page; page is ignored.
+func (c *FollowController) GetFeed(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	_, limit := getPaginationParams(ctx)
+	feed, err := c.followUseCase.GetFeed(userID, ctx.QueryParam("cursor"), limit)
+	if err != nil {
+		if err == domain.ErrInvalidInput {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid cursor",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get feed",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, feed)
+}
+
+// followListErrorResponse maps follower list errors to responses
+func followListErrorResponse(ctx echo.Context, err error, message string) error {
+	if err == domain.ErrNotFound {
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "User not found",
+		})
+	}
+	return ctx.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
@@ -7,8 +7,14 @@ package controller
 
 import (
 	"backend/domain"
+	"backend/usecase"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -25,7 +31,11 @@ func NewImageController(imageUseCase domain.ImageUseCase) *ImageController {
 	}
 }
 
+// maxFormFieldBytes limits each text field in the upload form
+const maxFormFieldBytes = 64 * 1024
+
 // UploadImage handles image upload
+// The multipart body is streamed, so text fields must be sent before the "image" part.
 func (c *ImageController) UploadImage(ctx echo.Context) error {
 	userID, err := getUserIDFromContext(ctx)
 	if err != nil {
@@ -34,42 +44,81 @@ func (c *ImageController) UploadImage(ctx echo.Context) error {
 		})
 	}
 
-	// 画像ファイルを取得
-	file, err := ctx.FormFile("image")
+	// ボディ全体の上限（テキストフィールドとマルチパートの区切りの分を上乗せ）
+	maxBytes := usecase.MaxUploadBytes()
+	req := ctx.Request()
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, maxBytes+4*maxFormFieldBytes)
+
+	reader, err := req.MultipartReader()
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, map[string]string{
-			"error": "No image file provided",
+			"error": "Invalid multipart form",
 		})
 	}
 
-	// フォームデータを取得
-	title := ctx.FormValue("title")
-	description := ctx.FormValue("description")
-	tags := ctx.FormValue("tags")
-
-	image, err := c.imageUseCase.UploadImageFromFile(userID, title, description, tags, file)
-	if err != nil {
-		switch err {
-		case domain.ErrInvalidFileType:
-			return ctx.JSON(http.StatusBadRequest, map[string]string{
-				"error": "Invalid file type. Only images are allowed",
-			})
-		case domain.ErrFileTooLarge:
+	fields := map[string]string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
 			return ctx.JSON(http.StatusBadRequest, map[string]string{
-				"error": "File too large. Maximum size is 10MB",
-			})
-		default:
-			return ctx.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "Failed to upload image",
+				"error": "No image file provided",
 			})
 		}
+		if err != nil {
+			return uploadErrorResponse(ctx, err, maxBytes)
+		}
+
+		if part.FormName() != "image" {
+			// フォームデータを取得
+			value, err := io.ReadAll(io.LimitReader(part, maxFormFieldBytes))
+			if err != nil {
+				return uploadErrorResponse(ctx, err, maxBytes)
+			}
+			fields[part.FormName()] = string(value)
+			continue
+		}
+
+		image, err := c.imageUseCase.UploadImage(userID, fields["title"], fields["description"], fields["tags"], part, part.FileName())
+		if err != nil {
+			return uploadErrorResponse(ctx, err, maxBytes)
+		}
+
+		return ctx.JSON(http.StatusCreated, image)
+	}
+}
+
+// uploadErrorResponse maps upload errors to responses
+func uploadErrorResponse(ctx echo.Context, err error, maxBytes int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = domain.ErrFileTooLarge
 	}
 
-	return ctx.JSON(http.StatusCreated, image)
+	switch err {
+	case domain.ErrInvalidFileType:
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid file type. Only images are allowed",
+		})
+	case domain.ErrFileTooLarge:
+		return ctx.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error": fmt.Sprintf("File too large. Maximum size is %dMB", maxBytes/(1024*1024)),
+		})
+	case domain.ErrImageTooLarge:
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Image dimensions too large",
+		})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to upload image",
+		})
+	}
 }
 
 // GetImage handles getting a single image
+// Anonymous viewers can open public and unlisted images; members-only and private images need a login.
 func (c *ImageController) GetImage(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
 	imageIDStr := ctx.Param("id")
 	imageID, err := strconv.ParseUint(imageIDStr, 10, 32)
 	if err != nil {
@@ -78,7 +127,7 @@ func (c *ImageController) GetImage(ctx echo.Context) error {
 		})
 	}
 
-	image, err := c.imageUseCase.GetImage(uint(imageID))
+	image, err := c.imageUseCase.GetImage(viewerID, uint(imageID))
 	if err != nil {
 		switch err {
 		case domain.ErrNotFound:
@@ -115,10 +164,12 @@ func (c *ImageController) GetUserImages(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, images)
 }
 
-// GetPublicImages handles getting public images
+// GetPublicImages handles getting public images, plus members-only images for logged-in viewers
 func (c *ImageController) GetPublicImages(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
 	page, limit := getPaginationParams(ctx)
-	images, err := c.imageUseCase.GetPublicImages(page, limit)
+	images, err := c.imageUseCase.GetPublicImages(viewerID, page, limit)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to get images",
@@ -152,9 +203,13 @@ func (c *ImageController) UpdateImage(ctx echo.Context) error {
 		})
 	}
 
-	image, err := c.imageUseCase.UpdateImage(userID, uint(imageID), req.Title, req.Description, req.Tags, req.IsPublic)
+	image, err := c.imageUseCase.UpdateImage(userID, uint(imageID), req.Title, req.Description, req.Tags, req.Visibility)
 	if err != nil {
 		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid visibility",
+			})
 		case domain.ErrNotFound:
 			return ctx.JSON(http.StatusNotFound, map[string]string{
 				"error": "Image not found",
@@ -222,15 +277,161 @@ func (c *ImageController) SearchImages(ctx echo.Context) error {
 		})
 	}
 
+	filter := getSearchFilter(ctx)
 	page, limit := getPaginationParams(ctx)
-	images, err := c.imageUseCase.SearchImages(query, page, limit)
+	images, err := c.imageUseCase.SearchImages(filter, page, limit)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to search images",
 		})
 	}
 
-	return ctx.JSON(http.StatusOK, images)
+	if !wantsFacets(ctx) {
+		return ctx.JSON(http.StatusOK, images)
+	}
+
+	facets, err := c.imageUseCase.GetImageFacets(filter)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to aggregate search facets",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, domain.SearchResponse{
+		Data:   images,
+		Facets: facets,
+	})
+}
+
+// GetTransformURL handles issuing a signed transformation URL
+func (c *ImageController) GetTransformURL(ctx echo.Context) error {
+	// 未ログインでも公開画像のURLは発行できる
+	userID, _ := getUserIDFromContext(ctx)
+
+	imageIDStr := ctx.Param("id")
+	imageID, err := strconv.ParseUint(imageIDStr, 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid image ID",
+		})
+	}
+
+	var opts domain.ImageTransformOptions
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, &opts); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid transformation parameters",
+		})
+	}
+
+	url, err := c.imageUseCase.GetTransformURL(userID, uint(imageID), opts)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid transformation parameters",
+			})
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Image not found",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create transformation URL",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"url": url,
+	})
+}
+
+// TransformImage handles serving a resized/converted image
+func (c *ImageController) TransformImage(ctx echo.Context) error {
+	imageIDStr := ctx.Param("id")
+	imageID, err := strconv.ParseUint(imageIDStr, 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid image ID",
+		})
+	}
+
+	var opts domain.ImageTransformOptions
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, &opts); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid transformation parameters",
+		})
+	}
+
+	result, err := c.imageUseCase.TransformImage(ctx.Request().Context(), uint(imageID), opts, ctx.QueryParam("sig"))
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid transformation parameters",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "Invalid or expired signature",
+			})
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Image not found",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to transform image",
+			})
+		}
+	}
+
+	// 同じパラメータの結果は変わらないので長期間キャッシュさせる。
+	// 期限付きのURLはログインが必要な画像なので、共有キャッシュには残さず期限までにする
+	etag := `"` + result.ETag + `"`
+	if result.ExpiresAt.IsZero() {
+		ctx.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		maxAge := int(time.Until(result.ExpiresAt).Seconds())
+		ctx.Response().Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+	}
+	ctx.Response().Header().Set("ETag", etag)
+	if ctx.Request().Header.Get("If-None-Match") == etag {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.Blob(http.StatusOK, result.ContentType, result.Data)
+}
+
+// GetSimilarImages handles listing identical and perceptually similar images
+func (c *ImageController) GetSimilarImages(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	imageIDStr := ctx.Param("id")
+	imageID, err := strconv.ParseUint(imageIDStr, 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid image ID",
+		})
+	}
+
+	matches, err := c.imageUseCase.GetSimilarImages(userID, uint(imageID))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Image not found",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get similar images",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, matches)
 }
 
 // getPaginationParams extracts pagination parameters from request
@@ -255,3 +456,180 @@ func getPaginationParams(ctx echo.Context) (int, int) {
 
 	return page, limit
 }
+
+// getSearchFilter extracts search and drill-down filters from request
+func getSearchFilter(ctx echo.Context) domain.SearchFilter {
+	filter := domain.SearchFilter{
+		Query: ctx.QueryParam("q"),
+		Month: ctx.QueryParam("month"),
+	}
+
+	// Logged-in viewers also search members-only works
+	filter.ViewerID, _ = getUserIDFromContext(ctx)
+
+	if tagsParam := ctx.QueryParam("tags"); tagsParam != "" {
+		for _, tag := range strings.Split(tagsParam, ",") {
+			if trimmed := strings.TrimSpace(tag); trimmed != "" {
+				filter.Tags = append(filter.Tags, trimmed)
+			}
+		}
+	}
+
+	if authorStr := ctx.QueryParam("author"); authorStr != "" {
+		if a, err := strconv.ParseUint(authorStr, 10, 32); err == nil {
+			filter.AuthorID = uint(a)
+		}
+	}
+
+	return filter
+}
+
+// wantsFacets reports whether the client asked for aggregated facets
+func wantsFacets(ctx echo.Context) bool {
+	facets, _ := strconv.ParseBool(ctx.QueryParam("facets"))
+	return facets
+}
+
+// ReplaceImageFile handles uploading a new revision of an image's file
+// The ID, title, tags and stats of the image are kept; only the "image" part of the form is read.
+func (c *ImageController) ReplaceImageFile(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	imageID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid image ID",
+		})
+	}
+
+	maxBytes := usecase.MaxUploadBytes()
+	req := ctx.Request()
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, maxBytes+4*maxFormFieldBytes)
+
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid multipart form",
+		})
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "No image file provided",
+			})
+		}
+		if err != nil {
+			return uploadErrorResponse(ctx, err, maxBytes)
+		}
+
+		if part.FormName() != "image" {
+			continue
+		}
+
+		image, err := c.imageUseCase.ReplaceImageFile(userID, uint(imageID), part, part.FileName())
+		if err != nil {
+			switch err {
+			case domain.ErrNotFound:
+				return ctx.JSON(http.StatusNotFound, map[string]string{
+					"error": "Image not found",
+				})
+			case domain.ErrForbidden:
+				return ctx.JSON(http.StatusForbidden, map[string]string{
+					"error": "You don't have permission to update this image",
+				})
+			default:
+				return uploadErrorResponse(ctx, err, maxBytes)
+			}
+		}
+
+		return ctx.JSON(http.StatusOK, image)
+	}
+}
+
+// GetImageRevisions handles listing the revisions of an image
+func (c *ImageController) GetImageRevisions(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	imageID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid image ID",
+		})
+	}
+
+	revisions, err := c.imageUseCase.GetImageRevisions(userID, uint(imageID))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Image not found",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "You don't have permission to view revisions of this image",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to get image revisions",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, revisions)
+}
+
+// RevertImage handles making an earlier revision the image's current file
+func (c *ImageController) RevertImage(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	imageID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid image ID",
+		})
+	}
+
+	revision, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil || revision < 1 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid revision",
+		})
+	}
+
+	image, err := c.imageUseCase.RevertImage(userID, uint(imageID), revision)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Image or revision not found",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "You don't have permission to update this image",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to revert image",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, image)
+}
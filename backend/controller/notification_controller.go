@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NotificationController handles notification requests
+type NotificationController struct {
+	notificationUseCase domain.NotificationUseCase
+}
+
+// NewNotificationController creates a new notification controller
+func NewNotificationController(notificationUseCase domain.NotificationUseCase) *NotificationController {
+	return &NotificationController{
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+// GetNotifications handles getting the user's notifications together with the unread count.
+// Pass ?unread=true to only list unread notifications.
+func (c *NotificationController) GetNotifications(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	unreadOnly := ctx.QueryParam("unread") == "true"
+	page, limit := getPaginationParams(ctx)
+	notifications, err := c.notificationUseCase.GetNotifications(userID, unreadOnly, page, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get notifications",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, notifications)
+}
+
+// MarkRead handles marking a notification as read
+func (c *NotificationController) MarkRead(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	notificationID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid notification ID",
+		})
+	}
+
+	err = c.notificationUseCase.MarkRead(userID, uint(notificationID))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Notification not found",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to mark notification as read",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Notification marked as read",
+	})
+}
+
+// MarkAllRead handles marking all of the user's notifications as read
+func (c *NotificationController) MarkAllRead(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	err = c.notificationUseCase.MarkAllRead(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to mark notifications as read",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "All notifications marked as read",
+	})
+}
+
+// GetPreferences handles getting which notification types the user receives
+func (c *NotificationController) GetPreferences(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	prefs, err := c.notificationUseCase.GetPreferences(userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get notification preferences",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles turning notification types on or off.
+// The body maps types to booleans, e.g. {"follow": false}; types left out are unchanged.
+func (c *NotificationController) UpdatePreferences(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req map[string]bool
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	prefs, err := c.notificationUseCase.UpdatePreferences(userID, req)
+	if err != nil {
+		if err == domain.ErrInvalidInput {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Unknown notification type",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to update notification preferences",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, prefs)
+}
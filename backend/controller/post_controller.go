@@ -4,7 +4,7 @@ import (
 	"backend/domain"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -44,9 +44,13 @@ func (c *PostController) CreatePost(ctx echo.Context) error {
 		})
 	}
 
-	post, err := c.postUseCase.CreatePost(userID, req.Title, req.Description, req.ImageIDs, req.Tags)
+	post, err := c.postUseCase.CreatePost(userID, req.Title, req.Description, req.ImageIDs, req.Tags, req.Visibility, req.Stages, req.Status, req.PublishAt)
 	if err != nil {
 		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid visibility, stages, status or publish time",
+			})
 		case domain.ErrForbidden:
 			return ctx.JSON(http.StatusForbidden, map[string]string{
 				"error": "You don't have permission to use one or more of the specified images",
@@ -62,7 +66,10 @@ func (c *PostController) CreatePost(ctx echo.Context) error {
 }
 
 // GetPost handles getting a single post
+// Anonymous viewers can open public and unlisted posts; members-only and private posts need a login.
 func (c *PostController) GetPost(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
 	postIDStr := ctx.Param("id")
 	postID, err := strconv.ParseUint(postIDStr, 10, 32)
 	if err != nil {
@@ -71,7 +78,7 @@ func (c *PostController) GetPost(ctx echo.Context) error {
 		})
 	}
 
-	post, err := c.postUseCase.GetPost(uint(postID))
+	post, err := c.postUseCase.GetPost(viewerID, uint(postID))
 	if err != nil {
 		switch err {
 		case domain.ErrNotFound:
@@ -108,10 +115,12 @@ func (c *PostController) GetUserPosts(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, posts)
 }
 
-// GetPublicPosts handles getting public posts
+// GetPublicPosts handles getting public posts, plus members-only posts for logged-in viewers
 func (c *PostController) GetPublicPosts(ctx echo.Context) error {
+	viewerID, _ := getUserIDFromContext(ctx)
+
 	page, limit := getPaginationParams(ctx)
-	posts, err := c.postUseCase.GetPublicPosts(page, limit)
+	posts, err := c.postUseCase.GetPublicPosts(viewerID, page, limit)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to get posts",
@@ -139,10 +148,12 @@ func (c *PostController) UpdatePost(ctx echo.Context) error {
 	}
 
 	var req struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Tags        string `json:"tags"`
-		IsPublic    bool   `json:"is_public"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		Tags        string     `json:"tags"`
+		Visibility  string     `json:"visibility"` // public, members, unlisted or private; empty keeps the current visibility
+		Status      string     `json:"status"`     // draft, scheduled or published; empty keeps the current status
+		PublishAt   *time.Time `json:"publish_at"` // required when scheduling
 	}
 
 	if err := ctx.Bind(&req); err != nil {
@@ -151,17 +162,25 @@ func (c *PostController) UpdatePost(ctx echo.Context) error {
 		})
 	}
 
-	post, err := c.postUseCase.UpdatePost(userID, uint(postID), req.Title, req.Description, req.Tags, req.IsPublic)
+	post, err := c.postUseCase.UpdatePost(userID, uint(postID), req.Title, req.Description, req.Tags, req.Visibility, req.Status, req.PublishAt)
 	if err != nil {
 		switch err {
 		case domain.ErrNotFound:
 			return ctx.JSON(http.StatusNotFound, map[string]string{
 				"error": "Post not found",
 			})
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid visibility, status or publish time",
+			})
 		case domain.ErrForbidden:
 			return ctx.JSON(http.StatusForbidden, map[string]string{
 				"error": "You don't have permission to update this post",
 			})
+		case domain.ErrConflict:
+			return ctx.JSON(http.StatusConflict, map[string]string{
+				"error": "The post was published meanwhile; reload it and try again",
+			})
 		default:
 			return ctx.JSON(http.StatusInternalServerError, map[string]string{
 				"error": "Failed to update post",
@@ -172,6 +191,57 @@ func (c *PostController) UpdatePost(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, post)
 }
 
+// SetPostStages handles replacing the process stages of a post
+func (c *PostController) SetPostStages(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	postID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid post ID",
+		})
+	}
+
+	var req struct {
+		Stages []domain.PostStageRequest `json:"stages"`
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	post, err := c.postUseCase.SetPostStages(userID, uint(postID), req.Stages)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Post not found",
+			})
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid stages",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "You don't have permission to update this post or use one or more of the specified images",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to update post stages",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, post)
+}
+
 // DeletePost handles deleting a post
 func (c *PostController) DeletePost(ctx echo.Context) error {
 	userID, err := getUserIDFromContext(ctx)
@@ -221,15 +291,7 @@ func (c *PostController) SearchPosts(ctx echo.Context) error {
 		})
 	}
 
-	page, limit := getPaginationParams(ctx)
-	posts, err := c.postUseCase.SearchPosts(query, page, limit)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to search posts",
-		})
-	}
-
-	return ctx.JSON(http.StatusOK, posts)
+	return c.searchPosts(ctx, getSearchFilter(ctx), "Failed to search posts")
 }
 
 // GetPostsByTags handles getting posts by tags
@@ -241,18 +303,32 @@ func (c *PostController) GetPostsByTags(ctx echo.Context) error {
 		})
 	}
 
-	tags := strings.Split(tagsParam, ",")
-	for i, tag := range tags {
-		tags[i] = strings.TrimSpace(tag)
-	}
+	return c.searchPosts(ctx, getSearchFilter(ctx), "Failed to get posts by tags")
+}
 
+// searchPosts responds with posts matching the filter and, if requested, their facets
+func (c *PostController) searchPosts(ctx echo.Context, filter domain.SearchFilter, errMessage string) error {
 	page, limit := getPaginationParams(ctx)
-	posts, err := c.postUseCase.GetPostsByTags(tags, page, limit)
+	posts, err := c.postUseCase.SearchPosts(filter, page, limit)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get posts by tags",
+			"error": errMessage,
 		})
 	}
 
-	return ctx.JSON(http.StatusOK, posts)
+	if !wantsFacets(ctx) {
+		return ctx.JSON(http.StatusOK, posts)
+	}
+
+	facets, err := c.postUseCase.GetPostFacets(filter)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to aggregate search facets",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, domain.SearchResponse{
+		Data:   posts,
+		Facets: facets,
+	})
 }
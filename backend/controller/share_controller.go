@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ShareController handles share link requests
+type ShareController struct {
+	shareUseCase domain.ShareUseCase
+}
+
+// NewShareController creates a new share controller
+func NewShareController(shareUseCase domain.ShareUseCase) *ShareController {
+	return &ShareController{
+		shareUseCase: shareUseCase,
+	}
+}
+
+// CreateImageShare handles minting a share link for an image
+func (c *ShareController) CreateImageShare(ctx echo.Context) error {
+	return c.createShare(ctx, domain.ShareTargetImage)
+}
+
+// CreatePostShare handles minting a share link for a post
+func (c *ShareController) CreatePostShare(ctx echo.Context) error {
+	return c.createShare(ctx, domain.ShareTargetPost)
+}
+
+// GetImageShares handles listing the share links of an image
+func (c *ShareController) GetImageShares(ctx echo.Context) error {
+	return c.getShares(ctx, domain.ShareTargetImage)
+}
+
+// GetPostShares handles listing the share links of a post
+func (c *ShareController) GetPostShares(ctx echo.Context) error {
+	return c.getShares(ctx, domain.ShareTargetPost)
+}
+
+// createShare mints a share link for the image or post in the :id parameter
+func (c *ShareController) createShare(ctx echo.Context, targetType string) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	targetID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid ID",
+		})
+	}
+
+	var req struct {
+		ExpiresInHours int `json:"expires_in_hours"` // defaults to 7 days, at most 30 days
+	}
+
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	share, err := c.shareUseCase.CreateShare(userID, targetType, uint(targetID), time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Expiry must be between 1 hour and 30 days",
+			})
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Not found",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "You don't have permission to share this " + targetType,
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create share link",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusCreated, share)
+}
+
+// getShares lists the share links of the image or post in the :id parameter
+func (c *ShareController) getShares(ctx echo.Context, targetType string) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	targetID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid ID",
+		})
+	}
+
+	shares, err := c.shareUseCase.GetShares(userID, targetType, uint(targetID))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Not found",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "You don't have permission to view share links of this " + targetType,
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to get share links",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, shares)
+}
+
+// RevokeShare handles revoking a share link
+func (c *ShareController) RevokeShare(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	shareID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid share link ID",
+		})
+	}
+
+	err = c.shareUseCase.RevokeShare(userID, uint(shareID))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Share link not found",
+			})
+		case domain.ErrForbidden:
+			return ctx.JSON(http.StatusForbidden, map[string]string{
+				"error": "You don't have permission to revoke this share link",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to revoke share link",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Share link revoked successfully",
+	})
+}
+
+// ResolveShare handles opening a shared image or post without logging in
+func (c *ShareController) ResolveShare(ctx echo.Context) error {
+	item, err := c.shareUseCase.ResolveShare(ctx.Param("token"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Share link is invalid, expired or revoked",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to open share link",
+		})
+	}
+
+	// Revoked links must stop working, so shared responses are not cached
+	ctx.Response().Header().Set("Cache-Control", "private, no-store")
+	return ctx.JSON(http.StatusOK, item)
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"backend/domain"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// streamHeartbeatInterval keeps idle connections from being closed by proxies
+	streamHeartbeatInterval = 25 * time.Second
+
+	// streamRetry tells EventSource how long to wait before reconnecting, in milliseconds
+	streamRetry = 3000
+)
+
+// StreamController handles Server-Sent Events requests
+type StreamController struct {
+	hub domain.StreamHub
+}
+
+// NewStreamController creates a new stream controller
+func NewStreamController(hub domain.StreamHub) *StreamController {
+	return &StreamController{
+		hub: hub,
+	}
+}
+
+// Stream handles the event stream of the user's notifications and the requested topics.
+// ?topics= is a comma-separated list and defaults to every topic. On reconnect, events sent
+// after the Last-Event-ID header (or ?last_event_id=) are replayed while they are still buffered.
+func (c *StreamController) Stream(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	topics := domain.StreamTopics
+	if topicsParam := ctx.QueryParam("topics"); topicsParam != "" {
+		topics = nil
+		for _, topic := range strings.Split(topicsParam, ",") {
+			if !isStreamTopic(topic) {
+				return ctx.JSON(http.StatusBadRequest, map[string]string{
+					"error": "Unknown topic: " + topic,
+				})
+			}
+			topics = append(topics, topic)
+		}
+	}
+
+	lastEventIDStr := ctx.Request().Header.Get("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = ctx.QueryParam("last_event_id")
+	}
+	var lastEventID uint64
+	if lastEventIDStr != "" {
+		lastEventID, err = strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid Last-Event-ID",
+			})
+		}
+	}
+
+	sub := c.hub.Subscribe(userID, topics, lastEventID)
+	defer c.hub.Unsubscribe(sub)
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", streamRetry); err != nil {
+		return nil
+	}
+	for _, event := range sub.Replay {
+		if err := writeStreamEvent(res, event); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and gets the rest replayed
+				return nil
+			}
+			if err := writeStreamEvent(res, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// writeStreamEvent writes an event in the text/event-stream format
+func writeStreamEvent(res *echo.Response, event domain.StreamEvent) error {
+	_, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err
+}
+
+// isStreamTopic reports whether topic can be subscribed to
+func isStreamTopic(topic string) bool {
+	for _, t := range domain.StreamTopics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
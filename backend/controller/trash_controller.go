@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TrashController handles trash bin requests
+type TrashController struct {
+	trashUseCase domain.TrashUseCase
+}
+
+// NewTrashController creates a new trash controller
+func NewTrashController(trashUseCase domain.TrashUseCase) *TrashController {
+	return &TrashController{
+		trashUseCase: trashUseCase,
+	}
+}
+
+// GetTrash handles listing the user's deleted images and posts
+func (c *TrashController) GetTrash(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	page, limit := getPaginationParams(ctx)
+	items, err := c.trashUseCase.GetTrash(userID, ctx.QueryParam("type"), page, limit)
+	if err != nil {
+		if err == domain.ErrInvalidInput {
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Type must be images or posts",
+			})
+		}
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get trash",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, items)
+}
+
+// Restore handles restoring a deleted image or post
+func (c *TrashController) Restore(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid ID",
+		})
+	}
+
+	item, err := c.trashUseCase.Restore(userID, ctx.Param("type"), uint(id))
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Type must be images or posts",
+			})
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Item not found in trash",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to restore item",
+			})
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, item)
+}
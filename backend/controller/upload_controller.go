@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"backend/domain"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tusVersion is the only tus protocol version we support
+const tusVersion = "1.0.0"
+
+// UploadController handles resumable uploads using the tus protocol
+type UploadController struct {
+	uploadUseCase domain.UploadUseCase
+	maxSize       int64
+}
+
+// NewUploadController creates a new upload controller
+func NewUploadController(uploadUseCase domain.UploadUseCase, maxSize int64) *UploadController {
+	return &UploadController{
+		uploadUseCase: uploadUseCase,
+		maxSize:       maxSize,
+	}
+}
+
+// Options handles tus capability discovery
+func (c *UploadController) Options(ctx echo.Context) error {
+	header := ctx.Response().Header()
+	header.Set("Tus-Resumable", tusVersion)
+	header.Set("Tus-Version", tusVersion)
+	header.Set("Tus-Extension", "creation,expiration,termination")
+	header.Set("Tus-Max-Size", strconv.FormatInt(c.maxSize, 10))
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// CreateUpload handles starting a new upload
+func (c *UploadController) CreateUpload(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+	if ctx.Request().Header.Get("Tus-Resumable") != tusVersion {
+		return unsupportedTusVersion(ctx)
+	}
+
+	length, err := strconv.ParseInt(ctx.Request().Header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid Upload-Length header",
+		})
+	}
+
+	metadata, err := parseUploadMetadata(ctx.Request().Header.Get("Upload-Metadata"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid Upload-Metadata header",
+		})
+	}
+
+	upload, err := c.uploadUseCase.CreateUpload(userID, length, metadata)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Upload-Length and filename metadata are required",
+			})
+		case domain.ErrFileTooLarge:
+			return ctx.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+				"error": fmt.Sprintf("File too large. Maximum size is %dMB", c.maxSize/(1024*1024)),
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to create upload",
+			})
+		}
+	}
+
+	setUploadHeaders(ctx, upload)
+	ctx.Response().Header().Set("Location", ctx.Request().URL.Path+"/"+upload.ID)
+	return ctx.NoContent(http.StatusCreated)
+}
+
+// GetUploadOffset handles HEAD requests for the current offset
+func (c *UploadController) GetUploadOffset(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.NoContent(http.StatusUnauthorized)
+	}
+	if ctx.Request().Header.Get("Tus-Resumable") != tusVersion {
+		return unsupportedTusVersion(ctx)
+	}
+
+	upload, err := c.uploadUseCase.GetUpload(userID, ctx.Param("id"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return ctx.NoContent(http.StatusNotFound)
+		}
+		return ctx.NoContent(http.StatusInternalServerError)
+	}
+
+	setUploadHeaders(ctx, upload)
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	return ctx.NoContent(http.StatusOK)
+}
+
+// PatchUpload handles appending data at the given offset
+func (c *UploadController) PatchUpload(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+	if ctx.Request().Header.Get("Tus-Resumable") != tusVersion {
+		return unsupportedTusVersion(ctx)
+	}
+
+	req := ctx.Request()
+	if req.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		return ctx.JSON(http.StatusUnsupportedMediaType, map[string]string{
+			"error": "Content-Type must be application/offset+octet-stream",
+		})
+	}
+
+	offset, err := strconv.ParseInt(req.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid Upload-Offset header",
+		})
+	}
+
+	upload, err := c.uploadUseCase.WriteChunk(userID, ctx.Param("id"), offset, req.Body)
+	if upload != nil {
+		setUploadHeaders(ctx, upload)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Upload not found",
+			})
+		case errors.Is(err, domain.ErrConflict):
+			return ctx.JSON(http.StatusConflict, map[string]string{
+				"error": "Upload-Offset does not match the current offset",
+			})
+		case errors.Is(err, domain.ErrLocked):
+			return ctx.JSON(http.StatusLocked, map[string]string{
+				"error": "Upload is being written by another request",
+			})
+		case errors.Is(err, domain.ErrInvalidFileType):
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Invalid file type. Only images are allowed",
+			})
+		case errors.Is(err, domain.ErrImageTooLarge):
+			return ctx.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Image dimensions too large",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to write upload",
+			})
+		}
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DeleteUpload handles terminating an upload
+func (c *UploadController) DeleteUpload(ctx echo.Context) error {
+	userID, err := getUserIDFromContext(ctx)
+	if err != nil {
+		return ctx.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Unauthorized",
+		})
+	}
+	if ctx.Request().Header.Get("Tus-Resumable") != tusVersion {
+		return unsupportedTusVersion(ctx)
+	}
+
+	err = c.uploadUseCase.DeleteUpload(userID, ctx.Param("id"))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return ctx.JSON(http.StatusNotFound, map[string]string{
+				"error": "Upload not found",
+			})
+		case domain.ErrLocked:
+			return ctx.JSON(http.StatusLocked, map[string]string{
+				"error": "Upload is being written by another request",
+			})
+		default:
+			return ctx.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to delete upload",
+			})
+		}
+	}
+
+	ctx.Response().Header().Set("Tus-Resumable", tusVersion)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// unsupportedTusVersion rejects requests for an unsupported protocol version
+func unsupportedTusVersion(ctx echo.Context) error {
+	ctx.Response().Header().Set("Tus-Version", tusVersion)
+	if ctx.Request().Method == http.MethodHead {
+		return ctx.NoContent(http.StatusPreconditionFailed)
+	}
+	return ctx.JSON(http.StatusPreconditionFailed, map[string]string{
+		"error": "Unsupported Tus-Resumable version",
+	})
+}
+
+// setUploadHeaders writes the tus headers describing the upload
+func setUploadHeaders(ctx echo.Context, upload *domain.Upload) {
+	header := ctx.Response().Header()
+	header.Set("Tus-Resumable", tusVersion)
+	header.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	header.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	header.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if upload.ImageID != nil {
+		header.Set("Upload-Image-Id", strconv.FormatUint(uint64(*upload.ImageID), 10))
+	}
+}
+
+// parseUploadMetadata decodes "key base64value,key2 base64value2"
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if header == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, domain.ErrInvalidInput
+		}
+
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
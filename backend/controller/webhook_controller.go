@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"backend/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookController handles administrator requests for outgoing webhooks
+type WebhookController struct {
+	webhookUseCase domain.WebhookUseCase
+}
+
+// NewWebhookController creates a new webhook controller
+func NewWebhookController(webhookUseCase domain.WebhookUseCase) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// webhookRequest is the body for creating and updating webhooks
+type webhookRequest struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Format string   `json:"format"` // discord or generic (default)
+	Events []string `json:"events"` // post.published, user.registered
+	Active *bool    `json:"active"` // defaults to true
+}
+
+// active returns whether the webhook should be enabled
+func (r *webhookRequest) active() bool {
+	return r.Active == nil || *r.Active
+}
+
+// GetWebhooks handles listing webhooks
+func (c *WebhookController) GetWebhooks(ctx echo.Context) error {
+	webhooks, err := c.webhookUseCase.GetWebhooks()
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to get webhooks",
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook handles creating a webhook; the response includes the generated signing secret
+func (c *WebhookController) CreateWebhook(ctx echo.Context) error {
+	var req webhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	webhook, err := c.webhookUseCase.CreateWebhook(req.Name, req.URL, req.Format, req.Events, req.active())
+	if err != nil {
+		return webhookErrorResponse(ctx, err, "Failed to create webhook")
+	}
+
+	return ctx.JSON(http.StatusCreated, webhook)
+}
+
+// UpdateWebhook handles updating a webhook
+func (c *WebhookController) UpdateWebhook(ctx echo.Context) error {
+	webhookID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	var req webhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request format",
+		})
+	}
+
+	webhook, err := c.webhookUseCase.UpdateWebhook(uint(webhookID), req.Name, req.URL, req.Format, req.Events, req.active())
+	if err != nil {
+		return webhookErrorResponse(ctx, err, "Failed to update webhook")
+	}
+
+	return ctx.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles deleting a webhook together with its delivery log
+func (c *WebhookController) DeleteWebhook(ctx echo.Context) error {
+	webhookID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	err = c.webhookUseCase.DeleteWebhook(uint(webhookID))
+	if err != nil {
+		return webhookErrorResponse(ctx, err, "Failed to delete webhook")
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]string{
+		"message": "Webhook deleted successfully",
+	})
+}
+
+// GetDeliveries handles the delivery log of a webhook
+func (c *WebhookController) GetDeliveries(ctx echo.Context) error {
+	webhookID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	page, limit := getPaginationParams(ctx)
+	deliveries, err := c.webhookUseCase.GetDeliveries(uint(webhookID), page, limit)
+	if err != nil {
+		return webhookErrorResponse(ctx, err, "Failed to get webhook deliveries")
+	}
+
+	return ctx.JSON(http.StatusOK, deliveries)
+}
+
+// PingWebhook handles queueing a test delivery
+func (c *WebhookController) PingWebhook(ctx echo.Context) error {
+	webhookID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	delivery, err := c.webhookUseCase.PingWebhook(uint(webhookID))
+	if err != nil {
+		return webhookErrorResponse(ctx, err, "Failed to queue test delivery")
+	}
+
+	return ctx.JSON(http.StatusAccepted, delivery)
+}
+
+// webhookErrorResponse maps webhook use case errors to responses
+func webhookErrorResponse(ctx echo.Context, err error, message string) error {
+	switch err {
+	case domain.ErrInvalidInput:
+		return ctx.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Name, an http(s) URL and at least one known event are required, and format must be discord or generic",
+		})
+	case domain.ErrNotFound:
+		return ctx.JSON(http.StatusNotFound, map[string]string{
+			"error": "Webhook not found",
+		})
+	default:
+		return ctx.JSON(http.StatusInternalServerError, map[string]string{
+			"error": message,
+		})
+	}
+}
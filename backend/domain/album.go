@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+// アルバムに入れる作品の種類
+const (
+	AlbumItemImage = "image"
+	AlbumItemPost  = "post"
+)
+
+// Album
+// @description: ユーザーがまとめた画像・投稿のコレクション（他のユーザーの作品も入れられる）
+type Album struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	UserID       uint        `json:"user_id" gorm:"not null;index"`
+	User         User        `json:"user" gorm:"foreignKey:UserID"`
+	Title        string      `json:"title" gorm:"not null"`
+	Description  string      `json:"description"`
+	Visibility   string      `json:"visibility" gorm:"not null;default:public"` // public, members, unlisted, private
+	CoverImageID *uint       `json:"cover_image_id"`
+	CoverImage   *Image      `json:"cover_image,omitempty" gorm:"foreignKey:CoverImageID"`
+	Items        []AlbumItem `json:"items,omitempty" gorm:"foreignKey:AlbumID"` // GetByIDでのみ読み込む
+	ItemCount    int         `json:"item_count" gorm:"->;-:migration"`          // 一覧で集計する
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// AlbumItem
+// @description: アルバムに入れた画像または投稿
+type AlbumItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	AlbumID   uint      `json:"album_id" gorm:"not null;index"`
+	Position  int       `json:"position" gorm:"not null"`  // 0から始まる表示順
+	ItemType  string    `json:"item_type" gorm:"not null"` // image, post
+	ImageID   *uint     `json:"image_id,omitempty"`
+	Image     *Image    `json:"image,omitempty" gorm:"foreignKey:ImageID"`
+	PostID    *uint     `json:"post_id,omitempty"`
+	Post      *Post     `json:"post,omitempty" gorm:"foreignKey:PostID"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VisibleTo
+// @description: 閲覧者がアルバムを閲覧できるか
+func (a *Album) VisibleTo(viewerID uint) bool {
+	return CanView(a.Visibility, a.UserID, viewerID)
+}
+
+// AlbumRepository
+// @description: アルバムデータ操作のインターフェース
+type AlbumRepository interface {
+	Create(album *Album) error                                                           // アルバムを作成
+	GetByID(id uint) (*Album, error)                                                     // アルバムを作品と一緒に表示順で取得
+	GetByUserID(userID uint, visibilities []string, offset, limit int) ([]*Album, error) // ユーザーのアルバムを取得（visibilitiesがnilの場合はすべて）
+	Update(album *Album) error                                                           // アルバムを更新
+	Delete(id uint) error                                                                // アルバムと中の作品の参照を削除
+	AddItem(item *AlbumItem) error                                                       // 作品を末尾に追加（すでに入っている場合はErrConflict）
+	RemoveItem(albumID, itemID uint) error                                               // 作品を取り除いて表示順を詰める
+	ReorderItems(albumID uint, itemIDs []uint) error                                     // 作品を指定した順番に並べ替える（すべての作品を指定する）
+}
+
+// AlbumUseCase
+// @description: アルバムのビジネスロジックのインターフェース
+type AlbumUseCase interface {
+	CreateAlbum(userID uint, title, description, visibility string) (*Album, error)                              // アルバムを作成
+	GetAlbum(viewerID, albumID uint) (*Album, error)                                                             // 閲覧できるアルバムを取得（閲覧できない作品は除く）
+	GetMyAlbums(userID uint, page, limit int) ([]*Album, error)                                                  // 自分のアルバムを取得
+	GetUserAlbums(viewerID uint, username string, page, limit int) ([]*Album, error)                             // ユーザーの公開アルバムを取得
+	GetUserAlbum(viewerID uint, username string, albumID uint) (*Album, error)                                   // ユーザーのアルバムを取得
+	UpdateAlbum(userID, albumID uint, title, description, visibility string, coverImageID *uint) (*Album, error) // アルバムを更新
+	DeleteAlbum(userID, albumID uint) error                                                                      // アルバムを削除（作品自体は削除しない）
+	AddAlbumItem(userID, albumID uint, itemType string, itemID uint) (*Album, error)                             // 作品を追加
+	RemoveAlbumItem(userID, albumID, itemID uint) (*Album, error)                                                // 作品を取り除く
+	ReorderAlbumItems(userID, albumID uint, itemIDs []uint) (*Album, error)                                      // 作品を並べ替える
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Blob
+// @description: 内容のSHA-256をキーにして保存した画像ファイル。同じ内容の画像は1つのBlobを共有する
+type Blob struct {
+	SHA256       string        `json:"sha256" gorm:"column:sha256;primaryKey;size:64"`
+	CloudinaryID string        `json:"cloudinary_id" gorm:"not null"`
+	URL          string        `json:"url" gorm:"not null"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	FileSize     int64         `json:"file_size"`
+	Format       string        `json:"format"`
+	Variants     ImageVariants `json:"variants" gorm:"type:jsonb;not null;default:'{}'"`
+	RefCount     int           `json:"ref_count" gorm:"not null;default:0"` // 参照している画像の数
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// BlobRepository
+// @description: Blobデータ操作のインターフェース
+type BlobRepository interface {
+	Acquire(sha256 string) (*Blob, error) // 既存のBlobの参照を増やす（存在しなければErrNotFound）
+	CreateOrAcquire(blob *Blob) (*Blob, error) // Blobを作成（同時に作成されていた場合は参照を増やす）
+	Release(sha256 string) (*Blob, error) // 参照を減らし、最後の参照だった場合は削除したBlobを返す
+	GetByCloudinaryID(publicID string) (*Blob, error) // 元画像のPublic IDでBlobを取得
+	SetVariants(publicID string, variants ImageVariants) error // Blobとそれを使う画像・版の縮小版を設定（Blobがなければ ErrNotFound）
+}
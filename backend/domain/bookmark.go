@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// ブックマークする作品の種類
+const (
+	BookmarkItemImage = "image"
+	BookmarkItemPost  = "post"
+)
+
+// BookmarkFolder
+// @description: ブックマークを整理するフォルダ（本人のみ）
+type BookmarkFolder struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Bookmark
+// @description: 資料として後で見返すために非公開で保存した作品（いいねとは別で、作品の所有者には件数だけが見える）
+type Bookmark struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ItemType  string    `json:"item_type" gorm:"not null"` // image, post
+	ImageID   *uint     `json:"image_id,omitempty"`
+	Image     *Image    `json:"image,omitempty" gorm:"foreignKey:ImageID"`
+	PostID    *uint     `json:"post_id,omitempty"`
+	Post      *Post     `json:"post,omitempty" gorm:"foreignKey:PostID"`
+	FolderID  *uint     `json:"folder_id"` // nullはフォルダなし
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookmarkRepository
+// @description: ブックマークデータ操作のインターフェース
+type BookmarkRepository interface {
+	Save(bookmark *Bookmark) error                                                   // ブックマークを作成（すでにある場合はフォルダを更新）
+	Delete(userID uint, itemType string, itemID uint) error                          // ブックマークを削除
+	GetByUserID(userID uint, folderID *uint, offset, limit int) ([]*Bookmark, error) // 閲覧できる作品のブックマークを新しい順に取得（folderIDがnilの場合はすべて）
+	CountByItems(itemType string, itemIDs []uint) (map[uint]int64, error)            // 作品ごとのブックマーク数
+	CreateFolder(folder *BookmarkFolder) error                                       // フォルダを作成（同じ名前がある場合はErrConflict）
+	GetFolder(id uint) (*BookmarkFolder, error)                                      // フォルダをIDで取得
+	GetFolders(userID uint) ([]*BookmarkFolder, error)                               // ユーザーのフォルダを名前順で取得
+	UpdateFolder(folder *BookmarkFolder) error                                       // フォルダを更新（同じ名前がある場合はErrConflict）
+	DeleteFolder(id uint) error                                                      // フォルダを削除（中のブックマークはフォルダなしになる）
+}
+
+// BookmarkUseCase
+// @description: ブックマークのビジネスロジックのインターフェース
+type BookmarkUseCase interface {
+	AddBookmark(userID uint, itemType string, itemID uint, folderID *uint) (*Bookmark, error) // 作品をブックマーク（すでにある場合はフォルダを移動）
+	RemoveBookmark(userID uint, itemType string, itemID uint) error                           // ブックマークを外す
+	GetBookmarks(userID uint, folderID *uint, page, limit int) ([]*Bookmark, error)           // ブックマークを取得（閲覧できなくなった作品は除く）
+	GetFolders(userID uint) ([]*BookmarkFolder, error)                                        // フォルダを取得
+	CreateFolder(userID uint, name string) (*BookmarkFolder, error)                           // フォルダを作成
+	RenameFolder(userID, folderID uint, name string) (*BookmarkFolder, error)                 // フォルダの名前を変更
+	DeleteFolder(userID, folderID uint) error                                                 // フォルダを削除
+}
@@ -23,7 +23,10 @@ var (
 	ErrInvalidPassword = errors.New("invalid password")
 	ErrFileTooLarge    = errors.New("file too large")
 	ErrInvalidFileType = errors.New("invalid file type")
+	ErrImageTooLarge   = errors.New("image dimensions too large")
 	ErrUploadFailed    = errors.New("upload failed")
+	ErrConflict        = errors.New("conflict")
+	ErrLocked          = errors.New("locked") // 他のリクエストが処理中
 )
 
 // @description: ページネーションリクエスト
@@ -67,16 +70,19 @@ type ImageUploadRequest struct {
 	Title       string `json:"title" validate:"required"`
 	Description string `json:"description"`
 	Tags        string `json:"tags"`
-	IsPublic    bool   `json:"is_public"`
+	Visibility  string `json:"visibility"`
 }
 
 // @description: 投稿作成リクエスト
 type PostCreateRequest struct {
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description"`
-	ImageIDs    []uint `json:"image_ids" validate:"required,min=1"`
-	Tags        string `json:"tags"`
-	IsPublic    bool   `json:"is_public"`
+	Title       string             `json:"title" validate:"required"`
+	Description string             `json:"description"`
+	ImageIDs    []uint             `json:"image_ids" validate:"required,min=1"`
+	Tags        string             `json:"tags"`
+	Visibility  string             `json:"visibility"` // public, members, unlisted, private（省略時はpublic）
+	Stages      []PostStageRequest `json:"stages"` // 制作過程（任意）
+	Status      string             `json:"status"` // draft, scheduled, published（省略時はすぐに公開）
+	PublishAt   *time.Time         `json:"publish_at"` // status=scheduledの公開日時
 }
 
 // @description: 検索リクエスト
@@ -86,6 +92,35 @@ type SearchRequest struct {
 	Limit int    `json:"limit" form:"limit" query:"limit"`
 }
 
+// @description: 検索条件（ファセットのドリルダウンにも使う）
+type SearchFilter struct {
+	Query    string   // タイトル・説明・タグの部分一致
+	Tags     []string // すべてを含むタグ
+	AuthorID uint     // 投稿者のユーザーID
+	Month    string   // 作成月（YYYY-MM）
+	ViewerID uint     // 閲覧者のユーザーID（0は未ログイン）。メンバー限定の作品を含めるかに使う
+}
+
+// @description: ファセットの値と件数
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// @description: 検索結果に対する集計
+type SearchFacets struct {
+	Tags    []FacetCount `json:"tags"`
+	Authors []FacetCount `json:"authors"`
+	Months  []FacetCount `json:"months"`
+}
+
+// @description: ファセット付き検索レスポンス
+type SearchResponse struct {
+	Data   interface{}   `json:"data"`
+	Facets *SearchFacets `json:"facets,omitempty"`
+}
+
 // @description: アプリケーション設定
 type Config struct {
 	Port                string
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// 重複フラグの状態
+const (
+	DuplicateStatusPending   = "pending"   // 未確認
+	DuplicateStatusConfirmed = "confirmed" // 無断転載と判断
+	DuplicateStatusDismissed = "dismissed" // 問題なし
+)
+
+// ImageMatch
+// @description: 同一または知覚的に似ている画像
+type ImageMatch struct {
+	Image    *Image `json:"image"`
+	Distance int    `json:"distance"` // 知覚ハッシュのハミング距離
+	Exact    bool   `json:"exact"`    // SHA-256が一致
+}
+
+// DuplicateFlag
+// @description: 他のユーザーの画像と似ているため管理者の確認待ちになった画像
+type DuplicateFlag struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	ImageID        uint       `json:"image_id" gorm:"not null;index"`
+	Image          Image      `json:"image" gorm:"foreignKey:ImageID"`
+	MatchedImageID uint       `json:"matched_image_id" gorm:"not null;index"`
+	MatchedImage   Image      `json:"matched_image" gorm:"foreignKey:MatchedImageID"`
+	Distance       int        `json:"distance"`
+	Exact          bool       `json:"exact"`
+	Status         string     `json:"status" gorm:"not null;default:pending;index"`
+	ReviewedBy     *uint      `json:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// DuplicateFlagRepository
+// @description: 重複フラグデータ操作のインターフェース
+type DuplicateFlagRepository interface {
+	Create(flag *DuplicateFlag) error // 重複フラグを作成
+	GetByID(id uint) (*DuplicateFlag, error) // 重複フラグをIDで取得
+	GetByStatus(status string, offset, limit int) ([]*DuplicateFlag, error) // 状態で重複フラグを取得
+	Update(flag *DuplicateFlag) error // 重複フラグを更新
+}
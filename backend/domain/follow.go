@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// Follow
+// @description: ユーザー間のフォロー関係
+type Follow struct {
+	FollowerID uint      `json:"follower_id" gorm:"primaryKey;autoIncrement:false"` // フォローした側
+	FolloweeID uint      `json:"followee_id" gorm:"primaryKey;autoIncrement:false"` // フォローされた側
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FollowUser
+// @description: フォロワー・フォロー中の一覧に出すユーザー（メールアドレスなどは含めない）
+type FollowUser struct {
+	ID         uint      `json:"id"`
+	Username   string    `json:"username"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	Avatar     string    `json:"avatar"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FeedCursor
+// @description: フィードの続きを取得する位置（この投稿より前のものを返す）
+type FeedCursor struct {
+	PublishAt time.Time
+	ID        uint
+}
+
+// FeedPage
+// @description: フィードの1ページ分
+type FeedPage struct {
+	Data       []*Post `json:"data"`
+	NextCursor string  `json:"next_cursor,omitempty"` // 続きがない場合は空
+}
+
+// FollowRepository
+// @description: フォローデータ操作のインターフェース
+type FollowRepository interface {
+	Follow(followerID, followeeID uint) error                           // フォローする（すでにフォローしている場合は何もしない）
+	Unfollow(followerID, followeeID uint) error                         // フォローを外す（フォローしていない場合はErrNotFound）
+	GetFollowers(userID uint, offset, limit int) ([]*FollowUser, error) // フォロワーを新しい順に取得
+	GetFollowing(userID uint, offset, limit int) ([]*FollowUser, error) // フォロー中のユーザーを新しい順に取得
+}
+
+// FeedRepository
+// @description: フォロー中のユーザーの投稿を読み出すインターフェース
+type FeedRepository interface {
+	GetFeed(userID uint, visibilities []string, before *FeedCursor, limit int) ([]*Post, error) // 公開済みの投稿を公開日時の新しい順に取得（beforeがnilの場合は先頭から）
+}
+
+// FollowUseCase
+// @description: フォローとフィードのビジネスロジックのインターフェース
+type FollowUseCase interface {
+	Follow(userID uint, username string) error                            // ユーザーをフォロー
+	Unfollow(userID uint, username string) error                          // フォローを外す
+	GetFollowers(username string, page, limit int) ([]*FollowUser, error) // ユーザーのフォロワーを取得
+	GetFollowing(username string, page, limit int) ([]*FollowUser, error) // ユーザーがフォローしているユーザーを取得
+	GetFeed(userID uint, cursor string, limit int) (*FeedPage, error)     // フォロー中のユーザーの最近の投稿を取得
+}
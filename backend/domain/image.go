@@ -6,6 +6,11 @@
 package domain
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"time"
 
@@ -26,14 +31,126 @@ type Image struct {
 	Height       int            `json:"height"`
 	FileSize     int64          `json:"file_size"`
 	Format       string         `json:"format"`
+	SHA256       string         `json:"sha256" gorm:"column:sha256;index"`
+	PHash        *int64         `json:"phash,string,omitempty" gorm:"column:phash"` // dHash（64ビットをそのまま符号付きで保存）
+	Variants     ImageVariants  `json:"variants" gorm:"type:jsonb;not null;default:'{}'"`
+	Metadata     ImageMetadata  `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+	Revision     int            `json:"revision" gorm:"not null;default:1"` // 現在のファイルの版
 	Tags         string         `json:"tags"` // Comma-separated tags
-	IsPublic     bool           `json:"is_public" gorm:"default:true"`
+	Visibility   string         `json:"visibility" gorm:"not null;default:public"` // public, members, unlisted, private
 	ViewCount    int            `json:"view_count" gorm:"default:0"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	Duplicates   []ImageMatch   `json:"duplicates,omitempty" gorm:"-"` // アップロード時に見つかった自分の重複画像
+	BookmarkCount *int64        `json:"bookmark_count,omitempty" gorm:"-"` // 所有者にのみ返すブックマーク数
 }
 
+// ImageVariant
+// @description: サーバー側で生成した縮小版の画像
+type ImageVariant struct {
+	CloudinaryID string `json:"-"`
+	URL          string `json:"url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Format       string `json:"format"`
+}
+
+// ImageVariants
+// @description: 長辺サイズ（"256"など）をキーにした縮小版の一覧
+type ImageVariants map[string]ImageVariant
+
+// variantRecord
+// @description: DB保存用（CloudinaryIDもJSONに含める）
+type variantRecord struct {
+	CloudinaryID string `json:"cloudinary_id"`
+	URL          string `json:"url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Format       string `json:"format"`
+}
+
+// Value
+// @description: driver.Valuerを実装
+func (v ImageVariants) Value() (driver.Value, error) {
+	records := make(map[string]variantRecord, len(v))
+	for name, variant := range v {
+		records[name] = variantRecord(variant)
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan
+// @description: sql.Scannerを実装
+func (v *ImageVariants) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*v = ImageVariants{}
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("unsupported type for ImageVariants: %T", src)
+	}
+
+	var records map[string]variantRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return err
+	}
+	*v = make(ImageVariants, len(records))
+	for name, record := range records {
+		(*v)[name] = ImageVariant(record)
+	}
+	return nil
+}
+
+// ImageMetadata
+// @description: アップロード時のEXIFから残すことを許可された情報（位置情報・端末情報は含めない）
+type ImageMetadata struct {
+	Software  string     `json:"software,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Value
+// @description: driver.Valuerを実装
+func (m ImageMetadata) Value() (driver.Value, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan
+// @description: sql.Scannerを実装
+func (m *ImageMetadata) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*m = ImageMetadata{}
+		return nil
+	case []byte:
+		return json.Unmarshal(s, m)
+	case string:
+		return json.Unmarshal([]byte(s), m)
+	default:
+		return fmt.Errorf("unsupported type for ImageMetadata: %T", src)
+	}
+}
+
+// 投稿の公開状態
+const (
+	PostStatusDraft     = "draft"     // 下書き（本人のみ）
+	PostStatusScheduled = "scheduled" // publish_atに公開予定
+	PostStatusPublished = "published" // 公開済み
+)
+
 // Post
 // @description: 投稿を含む画像
 type Post struct {
@@ -43,26 +160,61 @@ type Post struct {
 	Title       string         `json:"title" gorm:"not null"`
 	Description string         `json:"description"`
 	Images      []Image        `json:"images" gorm:"many2many:post_images;"`
+	Stages      []PostStage    `json:"stages,omitempty" gorm:"foreignKey:PostID"` // 制作過程（GetByIDでのみ読み込む）
+	BookmarkCount *int64       `json:"bookmark_count,omitempty" gorm:"-"` // 所有者にのみ返すブックマーク数
 	Tags        string         `json:"tags"` // Comma-separated tags
-	IsPublic    bool           `json:"is_public" gorm:"default:true"`
+	Visibility  string         `json:"visibility" gorm:"not null;default:public"` // public, members, unlisted, private
+	Status      string         `json:"status" gorm:"not null;default:published"` // draft, scheduled, published
+	PublishAt   *time.Time     `json:"publish_at"` // 公開（予定）日時。下書きはnull
+	AnnouncedAt *time.Time     `json:"-"` // 新着としてストリーム・Webhookで知らせた日時（知らせるのは一度だけ）
 	ViewCount   int            `json:"view_count" gorm:"default:0"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// ImageTransformOptions
+// @description: /img/:id の変換パラメータ
+type ImageTransformOptions struct {
+	Width    int    `query:"w"`
+	Height   int    `query:"h"`
+	Fit      string `query:"fit"` // contain, cover, fill
+	Format   string `query:"fmt"` // jpeg, png, webp
+	Revision int    `query:"v"`   // ファイルの版（0は現在の版）
+	Expires  int64  `query:"exp"` // URLの期限（Unix秒、0は無期限）
+}
+
+// TransformedImage
+// @description: 変換済みの画像データ
+type TransformedImage struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	ExpiresAt   time.Time // URLの期限（ゼロ値は無期限）
+}
+
 // ImageRepository
 // @description: 画像データ操作のインターフェース
 type ImageRepository interface {
 	Create(image *Image) error // 画像を作成
 	GetByID(id uint) (*Image, error) // 画像をIDで取得
 	GetByUserID(userID uint, offset, limit int) ([]*Image, error) // ユーザーIDで画像を取得
-	GetPublic(offset, limit int) ([]*Image, error) // 公開画像を取得
-	Update(image *Image) error // 画像を更新
+	GetPublic(viewerID uint, offset, limit int) ([]*Image, error) // 閲覧者の一覧に出る画像を取得
+	Update(image *Image) error // 画像のタイトル・説明・タグ・公開範囲を更新
 	Delete(id uint) error // 画像を削除
-	Search(query string, offset, limit int) ([]*Image, error) // 画像をクエリで検索
+	Search(filter SearchFilter, offset, limit int) ([]*Image, error) // 画像を検索条件で検索
 	GetByTags(tags []string, offset, limit int) ([]*Image, error) // タグで画像を取得
+	Facets(filter SearchFilter, limit int) (*SearchFacets, error) // 検索条件に一致する画像を集計
 	IncrementViewCount(id uint) error // 閲覧数を増やす
+	FindSimilar(image *Image, maxDistance int, viewerID uint, limit int) ([]*ImageMatch, error) // 同一または似ている画像を取得
+	GetDeletedByUserID(userID uint, deletedAfter time.Time, limit int) ([]*Image, error) // ゴミ箱の画像を削除が新しい順に取得
+	GetDeletedByID(id uint) (*Image, error) // ゴミ箱の画像をIDで取得
+	Restore(id uint) error // ゴミ箱の画像を元に戻す
+	PurgeDeleted(deletedBefore time.Time, limit int) (int, error) // 削除から時間が経った画像を完全に削除し、件数を返す
+	GetRevisions(imageID uint) ([]*ImageRevision, error) // 画像の版を新しい順に取得
+	GetRevision(imageID uint, revision int) (*ImageRevision, error) // 画像の版を取得
+	AddRevision(image *Image, revision *ImageRevision) error // 新しい版を追加して現在のファイルにする
+	SetRevision(image *Image, revision *ImageRevision) error // 既存の版を現在のファイルにする
 }
 
 // PostRepository
@@ -71,39 +223,59 @@ type PostRepository interface {
 	Create(post *Post) error // 投稿を作成
 	GetByID(id uint) (*Post, error) // 投稿をIDで取得
 	GetByUserID(userID uint, offset, limit int) ([]*Post, error) // ユーザーIDで投稿を取得
-	GetPublic(offset, limit int) ([]*Post, error) // 公開投稿を取得
-	Update(post *Post) error // 投稿を更新
+	GetPublic(viewerID uint, offset, limit int) ([]*Post, error) // 閲覧者の一覧に出る公開済みの投稿を取得
+	Update(post *Post, fromStatus string) error // 状態がfromStatusのままなら投稿を更新（変わっていればErrConflict）
 	Delete(id uint) error // 投稿を削除
-	Search(query string, offset, limit int) ([]*Post, error) // 投稿をクエリで検索
+	Search(filter SearchFilter, offset, limit int) ([]*Post, error) // 投稿を検索条件で検索
 	GetByTags(tags []string, offset, limit int) ([]*Post, error) // タグで投稿を取得
+	Facets(filter SearchFilter, limit int) (*SearchFacets, error) // 検索条件に一致する投稿を集計
 	IncrementViewCount(id uint) error // 閲覧数を増やす
+	GetDeletedByUserID(userID uint, deletedAfter time.Time, limit int) ([]*Post, error) // ゴミ箱の投稿を削除が新しい順に取得
+	GetDeletedByID(id uint) (*Post, error) // ゴミ箱の投稿をIDで取得
+	ReplaceStages(postID uint, stages []PostStage) error // 制作過程を入れ替える
+	PublishDue(now time.Time) ([]*Post, error) // 公開日時を過ぎた予約投稿を公開済みにして返す
+	MarkAnnounced(postID uint, at time.Time) (bool, error) // まだ知らせていない投稿を知らせたことにする（知らせ済みならfalse）
+	Restore(id uint) error // ゴミ箱の投稿を元に戻す
+	PurgeDeleted(deletedBefore time.Time, limit int) (int, error) // 削除から時間が経った投稿を完全に削除し、件数を返す
 }
 
 // ImageUseCase
 // @description: 画像ビジネスロジックのインターフェース
 type ImageUseCase interface {
-	UploadImage(userID uint, title, description, tags string, imageData []byte, filename string) (*Image, error) // 画像をアップロード
+	UploadImage(userID uint, title, description, tags string, r io.Reader, filename string) (*Image, error) // 画像をストリーミングでアップロード
 	UploadImageFromFile(userID uint, title, description, tags string, file *multipart.FileHeader) (*Image, error) // 画像をファイルからアップロード
-	GetImage(imageID uint) (*Image, error) // 画像をIDで取得
+	GetImage(viewerID, imageID uint) (*Image, error) // 閲覧できる画像をIDで取得
 	GetUserImages(userID uint, page, limit int) ([]*Image, error) // ユーザーIDで画像を取得
-	GetPublicImages(page, limit int) ([]*Image, error) // 公開画像を取得
-	UpdateImage(userID, imageID uint, title, description, tags string, isPublic bool) (*Image, error) // 画像を更新
+	GetPublicImages(viewerID uint, page, limit int) ([]*Image, error) // 閲覧者の一覧に出る画像を取得
+	UpdateImage(userID, imageID uint, title, description, tags, visibility string) (*Image, error) // 画像を更新（visibilityが空なら公開範囲は変えない）
 	DeleteImage(userID, imageID uint) error // 画像を削除
-	SearchImages(query string, page, limit int) ([]*Image, error) // 画像をクエリで検索
+	SearchImages(filter SearchFilter, page, limit int) ([]*Image, error) // 画像を検索条件で検索
+	GetImageFacets(filter SearchFilter) (*SearchFacets, error) // 画像検索のファセットを取得
 	GetImagesByTags(tags []string, page, limit int) ([]*Image, error) // タグで画像を取得
 	IncrementViewCount(imageID uint) error // 閲覧数を増やす
+	GetTransformURL(userID, imageID uint, opts ImageTransformOptions) (string, error) // 署名付きの変換URLを発行
+	TransformImage(ctx context.Context, imageID uint, opts ImageTransformOptions, signature string) (*TransformedImage, error) // 画像を変換
+	GetSimilarImages(userID, imageID uint) ([]*ImageMatch, error) // 同一または似ている画像を取得
+	GetDuplicateFlags(status string, page, limit int) ([]*DuplicateFlag, error) // 管理者の確認待ちの重複を取得
+	ReviewDuplicateFlag(reviewerID, flagID uint, status string) (*DuplicateFlag, error) // 重複フラグを確認済みにする
+	ReplaceImageFile(userID, imageID uint, r io.Reader, filename string) (*Image, error) // ファイルを新しい版に差し替え
+	GetImageRevisions(userID, imageID uint) ([]*ImageRevision, error) // 画像の版の履歴を取得（所有者のみ）
+	RevertImage(userID, imageID uint, revision int) (*Image, error) // 以前の版に戻す
 }
 
 // PostUseCase
 // @description: 投稿ビジネスロジックのインターフェース
 type PostUseCase interface {
-	CreatePost(userID uint, title, description string, imageIDs []uint, tags string) (*Post, error) // 投稿を作成
-	GetPost(postID uint) (*Post, error) // 投稿をIDで取得
+	CreatePost(userID uint, title, description string, imageIDs []uint, tags, visibility string, stages []PostStageRequest, status string, publishAt *time.Time) (*Post, error) // 投稿を作成
+	GetPost(viewerID, postID uint) (*Post, error) // 閲覧できる投稿をIDで取得
 	GetUserPosts(userID uint, page, limit int) ([]*Post, error) // ユーザーIDで投稿を取得
-	GetPublicPosts(page, limit int) ([]*Post, error) // 公開投稿を取得
-	UpdatePost(userID, postID uint, title, description, tags string, isPublic bool) (*Post, error) // 投稿を更新
+	GetPublicPosts(viewerID uint, page, limit int) ([]*Post, error) // 閲覧者の一覧に出る公開済みの投稿を取得
+	UpdatePost(userID, postID uint, title, description, tags, visibility string, status string, publishAt *time.Time) (*Post, error) // 投稿を更新（visibility・statusが空なら変えない）
 	DeletePost(userID, postID uint) error // 投稿を削除
-	SearchPosts(query string, page, limit int) ([]*Post, error) // 投稿をクエリで検索
+	SetPostStages(userID, postID uint, stages []PostStageRequest) (*Post, error) // 制作過程を設定
+	PublishScheduledPosts() error // 公開日時を過ぎた予約投稿を公開する
+	SearchPosts(filter SearchFilter, page, limit int) ([]*Post, error) // 投稿を検索条件で検索
+	GetPostFacets(filter SearchFilter) (*SearchFacets, error) // 投稿検索のファセットを取得
 	GetPostsByTags(tags []string, page, limit int) ([]*Post, error) // タグで投稿を取得
 	IncrementViewCount(postID uint) error // 閲覧数を増やす
 }
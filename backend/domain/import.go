@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// 取り込み元
+const (
+	ImportSourceDiscord = "discord" // DiscordChatExporterで書き出したチャンネル
+)
+
+// ImportedItem
+// @description: 他のサービスから取り込んだ投稿の記録（同じものを二度取り込まないために使う）
+type ImportedItem struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Source     string    `json:"source" gorm:"not null"`
+	ExternalID string    `json:"external_id" gorm:"not null"` // 取り込み元でのID（Discordのメッセージなど）
+	UserID     uint      `json:"user_id" gorm:"not null"`
+	PostID     uint      `json:"post_id" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ImportPost
+// @description: 取り込む投稿の内容
+type ImportPost struct {
+	Source      string
+	ExternalID  string
+	Username    string // 投稿者（取り込み元のアカウントから対応付けたユーザー）
+	Title       string
+	Description string
+	Tags        string
+	Visibility  string    // 空の場合はpublic
+	PostedAt    time.Time // 元の投稿日時（投稿の公開日時と画像の作成日時にする）
+	Files       []string  // 画像ファイルのパス
+}
+
+// ImportImage
+// @description: 投稿にまとめずに取り込む画像
+type ImportImage struct {
+	Username    string // アップロードするユーザー
+	Title       string
+	Description string
+	Tags        string
+	Visibility  string    // 空の場合はpublic
+	CreatedAt   time.Time // 元の作成日時（ゼロの場合は取り込んだ日時のまま）
+	File        string    // 画像ファイルのパス
+}
+
+// ImportRepository
+// @description: 取り込みデータ操作のインターフェース
+type ImportRepository interface {
+	Exists(source, externalID string) (bool, error)             // 取り込み済みかどうか
+	Save(item *ImportedItem, post *Post, imageIDs []uint) error // 投稿の作成・画像の紐付け・日時の書き換え・取り込みの記録をまとめて行う
+	BackdateImage(imageID uint, at time.Time) error             // 画像の作成日時を元の日時に書き換える
+}
+
+// ImportUseCase
+// @description: 取り込みのビジネスロジックのインターフェース
+type ImportUseCase interface {
+	IsImported(source, externalID string) (bool, error) // 取り込み済みかどうか
+	ImportPost(req ImportPost) (*Post, error)           // 画像をアップロードして投稿を作成（取り込み済みの場合はErrConflict）
+	ImportImage(req ImportImage) (*Image, error)        // 画像を1枚アップロード（どこまで取り込んだかは呼び出し元が管理する）
+}
@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// 通知の種類
+const (
+	NotificationFollow        = "follow"         // フォローされた
+	NotificationLike          = "like"           // 作品にいいねされた
+	NotificationComment       = "comment"        // 作品にコメントされた
+	NotificationPostPublished = "post_published" // 予約投稿が公開された
+)
+
+// NotificationTypes
+// @description: 設定画面に出す通知の種類
+var NotificationTypes = []string{
+	NotificationFollow,
+	NotificationLike,
+	NotificationComment,
+	NotificationPostPublished,
+}
+
+// 通知の対象の種類
+const (
+	NotificationTargetUser = "user"
+	NotificationTargetPost = "post"
+)
+
+// Notification
+// @description: ユーザーへのアプリ内通知。
+// 同じ対象への同じ種類の通知は未読の間1件にまとめ、行った人数を数える（「5人がいいねしました」）
+type Notification struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null"` // 受け取るユーザー
+	Type       string     `json:"type" gorm:"not null"`
+	ActorID    *uint      `json:"actor_id"` // 最後に行ったユーザー（システムからの通知はnull）
+	Actor      *User      `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	ActorCount int        `json:"actor_count" gorm:"not null;default:0"` // まとめた通知を行った人数
+	TargetType string     `json:"target_type" gorm:"not null"`
+	TargetID   uint       `json:"target_id" gorm:"not null"`
+	Message    string     `json:"message" gorm:"-"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"` // 最後にまとめられた日時
+}
+
+// NotificationEvent
+// @description: 通知のもとになる出来事
+type NotificationEvent struct {
+	Type        string
+	RecipientID uint
+	ActorID     uint // システムからの通知は0
+	TargetType  string
+	TargetID    uint
+}
+
+// NotificationList
+// @description: 通知の一覧と未読数
+type NotificationList struct {
+	Data        []*Notification `json:"data"`
+	UnreadCount int64           `json:"unread_count"`
+}
+
+// NotificationPreference
+// @description: 通知の種類ごとの受け取り設定（行がない種類は受け取る）
+type NotificationPreference struct {
+	UserID  uint   `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Type    string `json:"type" gorm:"primaryKey"`
+	Enabled bool   `json:"enabled" gorm:"not null"`
+}
+
+// NotificationRepository
+// @description: 通知データ操作のインターフェース
+type NotificationRepository interface {
+	Record(event NotificationEvent) error                                                 // 通知を保存（未読の同じ通知があればまとめる）
+	GetByUserID(userID uint, unreadOnly bool, offset, limit int) ([]*Notification, error) // 通知を新しい順に取得
+	CountUnread(userID uint) (int64, error)                                               // 未読の通知数
+	MarkRead(userID, id uint) error                                                       // 通知を既読にする（ユーザーの通知でなければErrNotFound）
+	MarkAllRead(userID uint) error                                                        // すべての通知を既読にする
+	GetPreferences(userID uint) ([]*NotificationPreference, error)                        // 保存されている受け取り設定
+	SavePreferences(prefs []*NotificationPreference) error                                // 受け取り設定を保存
+}
+
+// NotificationUseCase
+// @description: 通知のビジネスロジックのインターフェース
+type NotificationUseCase interface {
+	Notify(event NotificationEvent)                                                            // 通知を送る（失敗しても呼び出し元の処理は続ける）
+	GetNotifications(userID uint, unreadOnly bool, page, limit int) (*NotificationList, error) // 通知と未読数を取得
+	MarkRead(userID, notificationID uint) error                                                // 通知を既読にする
+	MarkAllRead(userID uint) error                                                             // すべての通知を既読にする
+	GetPreferences(userID uint) (map[string]bool, error)                                       // 種類ごとの受け取り設定を取得
+	UpdatePreferences(userID uint, prefs map[string]bool) (map[string]bool, error)             // 受け取り設定を更新
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// ストレージ操作の種類
+const (
+	OutboxActionDeleteImage      = "delete_image"      // Cloudinaryから画像を削除
+	OutboxActionGenerateVariants = "generate_variants" // アップロード時に作れなかった縮小版を元画像から作り直す
+)
+
+// ストレージ操作の状態
+const (
+	OutboxStatusPending = "pending" // 実行待ち（再試行を含む）
+	OutboxStatusFailed  = "failed"  // 再試行の上限に達した
+)
+
+// OutboxTask
+// @description: DBの変更と同じトランザクションで記録し、後からワーカーが実行するストレージ操作
+type OutboxTask struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Action        string    `json:"action" gorm:"not null"`
+	PublicID      string    `json:"public_id" gorm:"not null"`
+	Status        string    `json:"status" gorm:"not null;default:pending"`
+	Attempts      int       `json:"attempts" gorm:"not null;default:0"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"not null;index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName
+// @description: テーブル名
+func (OutboxTask) TableName() string {
+	return "storage_outbox"
+}
+
+// OutboxRepository
+// @description: ストレージ操作の記録のインターフェース
+type OutboxRepository interface {
+	Enqueue(tasks ...*OutboxTask) error // ストレージ操作を記録
+	Claim(now time.Time, lease time.Duration, limit int) ([]*OutboxTask, error) // 実行時刻になった操作を取得し、lease の間は他のワーカーに渡さない
+	Complete(id uint) error // 完了した操作を削除
+	Update(task *OutboxTask) error // 失敗した操作の再試行時刻などを更新
+	PendingPublicIDs() ([]string, error) // 削除待ちのPublic IDを取得
+	ReferencedPublicIDs() ([]string, error) // 画像・Blobから参照されているPublic IDを取得
+	IsReferenced(publicID string) (bool, error) // 画像・Blobから参照されているか
+}
+
+// StorageUseCase
+// @description: ストレージとDBの整合性を保つ処理のインターフェース
+type StorageUseCase interface {
+	ProcessOutbox() error // 実行時刻になったストレージ操作を実行
+	ReconcileStorage() error // どこからも参照されていないストレージ上の画像を報告または削除
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// ImageRevision
+// @description: 画像のファイルの版。版ごとにBlobを1つ参照し、画像は現在の版の内容を持つ
+type ImageRevision struct {
+	ID           uint          `json:"id" gorm:"primaryKey"`
+	ImageID      uint          `json:"image_id" gorm:"not null;uniqueIndex:idx_image_revisions_image_revision"`
+	Revision     int           `json:"revision" gorm:"not null;uniqueIndex:idx_image_revisions_image_revision"`
+	CloudinaryID string        `json:"cloudinary_id" gorm:"not null"`
+	URL          string        `json:"url" gorm:"not null"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	FileSize     int64         `json:"file_size"`
+	Format       string        `json:"format"`
+	SHA256       string        `json:"sha256" gorm:"column:sha256"`
+	PHash        *int64        `json:"phash,string,omitempty" gorm:"column:phash"`
+	Variants     ImageVariants `json:"variants" gorm:"type:jsonb;not null;default:'{}'"`
+	Metadata     ImageMetadata `json:"metadata" gorm:"type:jsonb;not null;default:'{}'"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// RevisionOf
+// @description: 画像の現在のファイルを版にする
+func RevisionOf(image *Image) *ImageRevision {
+	return &ImageRevision{
+		ImageID:      image.ID,
+		Revision:     image.Revision,
+		CloudinaryID: image.CloudinaryID,
+		URL:          image.URL,
+		Width:        image.Width,
+		Height:       image.Height,
+		FileSize:     image.FileSize,
+		Format:       image.Format,
+		SHA256:       image.SHA256,
+		PHash:        image.PHash,
+		Variants:     image.Variants,
+		Metadata:     image.Metadata,
+	}
+}
+
+// ApplyRevision
+// @description: 版のファイルを画像の現在のファイルにする（タイトルや閲覧数などはそのまま）
+func (i *Image) ApplyRevision(revision *ImageRevision) {
+	i.Revision = revision.Revision
+	i.CloudinaryID = revision.CloudinaryID
+	i.URL = revision.URL
+	i.Width = revision.Width
+	i.Height = revision.Height
+	i.FileSize = revision.FileSize
+	i.Format = revision.Format
+	i.SHA256 = revision.SHA256
+	i.PHash = revision.PHash
+	i.Variants = revision.Variants
+	i.Metadata = revision.Metadata
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// 共有リンクの対象の種類
+const (
+	ShareTargetImage = "image"
+	ShareTargetPost  = "post"
+)
+
+// ShareLink
+// @description: 非公開の作品をクラブ外の人に見せるための期限付きリンク
+type ShareLink struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	TargetType string     `json:"target_type" gorm:"not null"` // image, post
+	TargetID   uint       `json:"target_id" gorm:"not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ViewCount  int        `json:"view_count" gorm:"not null;default:0"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Token      string     `json:"token,omitempty" gorm:"-"` // 署名付きトークン（発行時のみ）
+	URL        string     `json:"url,omitempty" gorm:"-"`   // /s/:token（発行時のみ）
+}
+
+// SharedItem
+// @description: 共有リンクから開いた作品
+type SharedItem struct {
+	Type      string    `json:"type"` // image, post
+	Image     *Image    `json:"image,omitempty"`
+	Post      *Post     `json:"post,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ShareLinkRepository
+// @description: 共有リンクデータ操作のインターフェース
+type ShareLinkRepository interface {
+	Create(share *ShareLink) error                                      // 共有リンクを作成
+	GetByID(id uint) (*ShareLink, error)                                // 共有リンクをIDで取得
+	GetByTarget(targetType string, targetID uint) ([]*ShareLink, error) // 作品の共有リンクを新しい順に取得
+	Revoke(id uint, now time.Time) error                                // 共有リンクを無効にする
+	RecordView(id uint, now time.Time) (*ShareLink, error)              // 有効な共有リンクの閲覧数を増やして返す（無効ならErrNotFound）
+}
+
+// ShareUseCase
+// @description: 共有リンクのビジネスロジックのインターフェース
+type ShareUseCase interface {
+	CreateShare(userID uint, targetType string, targetID uint, ttl time.Duration) (*ShareLink, error) // 作品の共有リンクを発行（所有者のみ）
+	GetShares(userID uint, targetType string, targetID uint) ([]*ShareLink, error)                    // 作品の共有リンクと閲覧数を取得（所有者のみ）
+	RevokeShare(userID, shareID uint) error                                                           // 共有リンクを無効にする
+	ResolveShare(token string) (*SharedItem, error)                                                   // トークンを検証して作品を取得（ログイン不要）
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// PostStage
+// @description: 投稿の制作過程の段階（ラフ→線画→着色など）。完成画像とは別に順番付きで表示する
+type PostStage struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PostID    uint      `json:"post_id" gorm:"not null;index"`
+	Position  int       `json:"position" gorm:"not null"` // 0から始まる表示順
+	ImageID   uint      `json:"image_id" gorm:"not null"`
+	Image     *Image    `json:"image,omitempty" gorm:"foreignKey:ImageID"` // ゴミ箱に入った画像はnull
+	Label     string    `json:"label" gorm:"not null"`                     // 段階の名前（例: ラフ、線画、着色）
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PostStageRequest
+// @description: 制作過程の段階の入力
+type PostStageRequest struct {
+	ImageID uint   `json:"image_id" validate:"required"`
+	Label   string `json:"label" validate:"required"`
+	Note    string `json:"note"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+// 全員に配信するトピック
+const (
+	StreamTopicPublicPosts = "public_posts" // 新しく公開された一般公開の投稿
+)
+
+// StreamTopics
+// @description: 購読できるトピック
+var StreamTopics = []string{
+	StreamTopicPublicPosts,
+}
+
+// ストリームで送るイベントの種類
+const (
+	StreamEventNotification  = "notification" // 通知が届いた
+	StreamEventPostPublished = "post"         // 投稿が公開された
+)
+
+// StreamEvent
+// @description: Server-Sent Eventsで送るイベント
+type StreamEvent struct {
+	ID   uint64 // 再接続時のLast-Event-IDに使う連番
+	Type string
+	Data []byte // JSON
+}
+
+// StreamSubscription
+// @description: ストリームの購読
+type StreamSubscription struct {
+	Replay []StreamEvent      // Last-Event-IDより後に送られていたイベント
+	Events <-chan StreamEvent // 購読後のイベント（受け取りが遅れて切断された場合は閉じる）
+}
+
+// StreamHub
+// @description: ユーザー宛て・トピック宛てのイベントを接続中のクライアントに配るインターフェース
+type StreamHub interface {
+	PublishToUser(userID uint, eventType string, data interface{})                  // ユーザー宛てに送る
+	PublishToTopic(topic, eventType string, data interface{})                       // トピックの購読者に送る
+	Subscribe(userID uint, topics []string, lastEventID uint64) *StreamSubscription // 購読を開始（lastEventIDが0の場合は再送しない）
+	Unsubscribe(sub *StreamSubscription)                                            // 購読をやめる
+}
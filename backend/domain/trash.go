@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ゴミ箱に入る項目の種類（URLの :type）
+const (
+	TrashTypeImages = "images"
+	TrashTypePosts  = "posts"
+)
+
+// TrashItem
+// @description: 削除されてから保持期間内の画像または投稿
+type TrashItem struct {
+	Type      string      `json:"type"`
+	ID        uint        `json:"id"`
+	Title     string      `json:"title"`
+	DeletedAt time.Time   `json:"deleted_at"`
+	PurgeAt   time.Time   `json:"purge_at"` // この時刻を過ぎると完全に削除される
+	Item      interface{} `json:"item"`
+}
+
+// TrashUseCase
+// @description: ゴミ箱のビジネスロジックのインターフェース
+type TrashUseCase interface {
+	GetTrash(userID uint, itemType string, page, limit int) ([]*TrashItem, error) // ゴミ箱の中身を削除が新しい順に取得（itemTypeが空の場合は両方）
+	Restore(userID uint, itemType string, id uint) (*TrashItem, error) // ゴミ箱から元に戻す
+	PurgeExpired() error // 保持期間を過ぎたものを完全に削除
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+// Upload
+// @description: tusプロトコルによる再開可能なアップロード
+type Upload struct {
+	ID          string    `json:"id" gorm:"primaryKey;size:32"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Length      int64     `json:"length" gorm:"column:upload_length;not null"`
+	Offset      int64     `json:"offset" gorm:"column:upload_offset;not null;default:0"`
+	Filename    string    `json:"filename" gorm:"not null"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Tags        string    `json:"tags"`
+	ImageID     *uint     `json:"image_id"` // 完了後に作成された画像
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UploadRepository
+// @description: アップロードデータ操作のインターフェース
+type UploadRepository interface {
+	Create(upload *Upload) error // アップロードを作成
+	GetByID(id string) (*Upload, error) // アップロードをIDで取得
+	Update(upload *Upload) error // アップロードを更新
+	Delete(id string) error // アップロードを削除
+	Lock(id string, now, until time.Time) (bool, error) // 誰もロックしていなければuntilまでロックする（ロックできなければfalse）
+	Unlock(id string) error // ロックを外す
+	GetExpired(now time.Time, limit int) ([]*Upload, error) // 期限切れのアップロードを取得
+}
+
+// UploadUseCase
+// @description: 再開可能アップロードのビジネスロジックのインターフェース
+type UploadUseCase interface {
+	CreateUpload(userID uint, length int64, metadata map[string]string) (*Upload, error) // アップロードを開始
+	GetUpload(userID uint, uploadID string) (*Upload, error) // アップロードの状態を取得
+	WriteChunk(userID uint, uploadID string, offset int64, r io.Reader) (*Upload, error) // データを書き込み、揃ったら画像を作成
+	DeleteUpload(userID uint, uploadID string) error // アップロードを中止
+	PurgeExpiredUploads() error // 期限切れのアップロードを削除
+}
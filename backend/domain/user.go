@@ -16,6 +16,7 @@ type User struct {
 	LastName  string         `json:"last_name"`
 	Avatar    string         `json:"avatar"` // Cloudinary URL
 	IsActive  bool           `json:"is_active" gorm:"default:true"`
+	IsAdmin   bool           `json:"is_admin" gorm:"default:false"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
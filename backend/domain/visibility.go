@@ -0,0 +1,68 @@
+package domain
+
+// 作品（画像・投稿）の公開範囲
+const (
+	VisibilityPublic   = "public"   // 誰でも閲覧でき、一覧・検索に出る
+	VisibilityMembers  = "members"  // ログインしたメンバーのみ閲覧でき、メンバーの一覧・検索に出る
+	VisibilityUnlisted = "unlisted" // URLを知っていれば誰でも閲覧できるが、一覧・検索には出ない
+	VisibilityPrivate  = "private"  // 所有者のみ
+)
+
+// ValidVisibility
+// @description: 公開範囲の値が正しいか
+func ValidVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPublic, VisibilityMembers, VisibilityUnlisted, VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// CanView
+// @description: 閲覧者が作品を直接（IDやURLで）閲覧できるか。viewerIDが0の場合は未ログイン
+func CanView(visibility string, ownerID, viewerID uint) bool {
+	if viewerID != 0 && viewerID == ownerID {
+		return true
+	}
+
+	switch visibility {
+	case VisibilityPublic, VisibilityUnlisted:
+		return true
+	case VisibilityMembers:
+		return viewerID != 0
+	}
+	return false
+}
+
+// ListedVisibilities
+// @description: 閲覧者の一覧・検索に出る公開範囲
+func ListedVisibilities(viewerID uint) []string {
+	if viewerID == 0 {
+		return []string{VisibilityPublic}
+	}
+	return []string{VisibilityPublic, VisibilityMembers}
+}
+
+// VisibleTo
+// @description: 閲覧者が画像を閲覧できるか
+func (i *Image) VisibleTo(viewerID uint) bool {
+	return CanView(i.Visibility, i.UserID, viewerID)
+}
+
+// VisibleTo
+// @description: 閲覧者が投稿を閲覧できるか（下書き・予約投稿は所有者のみ）
+func (p *Post) VisibleTo(viewerID uint) bool {
+	if p.Status != PostStatusPublished && (viewerID == 0 || viewerID != p.UserID) {
+		return false
+	}
+	return CanView(p.Visibility, p.UserID, viewerID)
+}
+
+// ViewableVisibilities
+// @description: 閲覧者がURLで開ける他のユーザーの作品の公開範囲（CanViewと同じ条件をクエリで使う）
+func ViewableVisibilities(viewerID uint) []string {
+	if viewerID == 0 {
+		return []string{VisibilityPublic, VisibilityUnlisted}
+	}
+	return []string{VisibilityPublic, VisibilityMembers, VisibilityUnlisted}
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhookで送る出来事
+const (
+	WebhookEventPostPublished  = "post.published"  // 一般公開の投稿が公開された
+	WebhookEventUserRegistered = "user.registered" // 新しいメンバーが登録した
+	WebhookEventPing           = "ping"            // 管理画面からの送信テスト
+)
+
+// WebhookEvents
+// @description: 購読できる出来事
+var WebhookEvents = []string{
+	WebhookEventPostPublished,
+	WebhookEventUserRegistered,
+}
+
+// Webhookの送信形式
+const (
+	WebhookFormatDiscord = "discord" // DiscordのWebhook（埋め込み）
+	WebhookFormatGeneric = "generic" // 汎用のJSON
+)
+
+// 送信の状態
+const (
+	WebhookDeliveryPending   = "pending"   // 送信待ち（再試行を含む）
+	WebhookDeliverySucceeded = "succeeded" // 送信できた
+	WebhookDeliveryFailed    = "failed"    // 再試行の上限に達した
+)
+
+// Webhook
+// @description: 管理者が設定する外部への通知先
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	URL       string    `json:"url" gorm:"not null"`
+	Format    string    `json:"format" gorm:"not null"` // discord, generic
+	Secret    string    `json:"secret" gorm:"not null"` // 署名の鍵（管理者にのみ返す）
+	Events    string    `json:"events" gorm:"not null"` // Comma-separated events
+	Active    bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes
+// @description: 出来事を購読しているかどうか（pingはすべてのWebhookに送れる）
+func (w *Webhook) Subscribes(event string) bool {
+	if event == WebhookEventPing {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery
+// @description: Webhookの送信。送信待ちの列と送信履歴を兼ねる
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null"`
+	Event          string     `json:"event" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"not null"` // 送信形式に合わせて作ったJSON（再試行でも同じ内容を送る）
+	Status         string     `json:"status" gorm:"not null;default:pending"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseStatus int        `json:"response_status"` // 最後の応答のHTTPステータス（応答がなければ0）
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookEvent
+// @description: Webhookで送る出来事の内容
+type WebhookEvent struct {
+	Type        string
+	Title       string      // Discordの埋め込みのタイトル
+	Description string      // Discordの埋め込みの本文
+	URL         string      // 作品やユーザーのページ（相対パスの場合はサイトのURLを前に付ける）
+	ImageURL    string      // Discordの埋め込みに出す画像
+	Author      string      // Discordの埋め込みの投稿者名
+	Data        interface{} // 汎用のJSONのdata
+	OccurredAt  time.Time
+}
+
+// WebhookRepository
+// @description: Webhookデータ操作のインターフェース
+type WebhookRepository interface {
+	Create(webhook *Webhook) error                                                             // Webhookを作成
+	GetByID(id uint) (*Webhook, error)                                                         // WebhookをIDで取得
+	GetAll() ([]*Webhook, error)                                                               // すべてのWebhookを取得
+	GetActive() ([]*Webhook, error)                                                            // 有効なWebhookを取得
+	Update(webhook *Webhook) error                                                             // Webhookを更新
+	Delete(id uint) error                                                                      // Webhookと送信履歴を削除
+	EnqueueDeliveries(deliveries ...*WebhookDelivery) error                                    // 送信を記録
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error) // 送信時刻になったものを取得し、leaseの間は他のワーカーに渡さない
+	UpdateDelivery(delivery *WebhookDelivery) error                                            // 送信の結果を更新
+	GetDeliveries(webhookID uint, offset, limit int) ([]*WebhookDelivery, error)               // 送信履歴を新しい順に取得
+	PurgeDeliveries(before time.Time) (int64, error)                                           // 古い送信履歴を削除
+}
+
+// WebhookUseCase
+// @description: Webhookのビジネスロジックのインターフェース
+type WebhookUseCase interface {
+	Dispatch(event WebhookEvent)                                                                     // 出来事を購読しているWebhookへの送信を記録（失敗しても呼び出し元の処理は続ける）
+	CreateWebhook(name, url, format string, events []string, active bool) (*Webhook, error)          // Webhookを作成（署名の鍵は自動で作る）
+	UpdateWebhook(id uint, name, url, format string, events []string, active bool) (*Webhook, error) // Webhookを更新
+	DeleteWebhook(id uint) error                                                                     // Webhookを削除
+	GetWebhooks() ([]*Webhook, error)                                                                // すべてのWebhookを取得
+	GetDeliveries(webhookID uint, page, limit int) ([]*WebhookDelivery, error)                       // 送信履歴を取得
+	PingWebhook(id uint) (*WebhookDelivery, error)                                                   // 送信テストを記録
+	DeliverWebhooks() error                                                                          // 送信時刻になったものを送る（バックグラウンドで定期的に実行）
+	PurgeDeliveries() error                                                                          // 保存期間を過ぎた送信履歴を削除（バックグラウンドで定期的に実行）
+}
@@ -0,0 +1,284 @@
+// discord.go
+
+package main
+
+import (
+	"backend/domain"
+	"backend/infrastructure/db"
+	"backend/infrastructure/imaging"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDiscordTitleLen is the length a title taken from a message's first line is cut to
+const maxDiscordTitleLen = 100
+
+// discordExport is the part of a DiscordChatExporter JSON export the importer reads
+type discordExport struct {
+	Channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channel"`
+	Messages []discordMessage `json:"messages"`
+}
+
+// discordMessage is a single message of an export
+type discordMessage struct {
+	ID          string              `json:"id"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Content     string              `json:"content"`
+	Author      discordAuthor       `json:"author"`
+	Attachments []discordAttachment `json:"attachments"`
+}
+
+// discordAuthor is the author of a message
+type discordAuthor struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Discriminator string `json:"discriminator"`
+}
+
+// discordAttachment is a file attached to a message; with --media the URL is the downloaded file's path
+type discordAttachment struct {
+	URL      string `json:"url"`
+	FileName string `json:"fileName"`
+}
+
+// tag is the author's legacy Discord tag, e.g. "alice#1234"
+func (a discordAuthor) tag() string {
+	if a.Discriminator == "" || a.Discriminator == "0000" {
+		return a.Name
+	}
+	return a.Name + "#" + a.Discriminator
+}
+
+// discordSummary counts the outcome of each message
+type discordSummary struct {
+	imported     int
+	skipped      int
+	withoutImage int
+	unmapped     map[string]int
+	failed       int
+}
+
+// importDiscord imports the image messages of a DiscordChatExporter JSON export as posts
+func importDiscord(args []string) error {
+	flags := flag.NewFlagSet("import-discord", flag.ExitOnError)
+	exportPath := flags.String("export", "", "DiscordChatExporterで書き出したJSONファイル（必須）")
+	mediaDir := flags.String("media", "", "添付ファイルをダウンロードしたディレクトリ（デフォルトは<export>_Files）")
+	usersPath := flags.String("users", "", `Discordのアカウントとユーザー名の対応を書いたJSONファイル（必須）。キーはアカウントID・"name#1234"・名前のいずれか`)
+	tags := flags.String("tags", "", "取り込んだ投稿と画像に付けるタグ（カンマ区切り）")
+	visibility := flags.String("visibility", domain.VisibilityPublic, "取り込んだ投稿と画像の公開範囲")
+	dryRun := flags.Bool("dry-run", false, "取り込まずに対象のメッセージを表示")
+	flags.Parse(args)
+
+	if *exportPath == "" || *usersPath == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *mediaDir == "" {
+		*mediaDir = *exportPath + "_Files"
+	}
+
+	export, err := readDiscordExport(*exportPath)
+	if err != nil {
+		return err
+	}
+	users, err := readUserMapping(*usersPath)
+	if err != nil {
+		return err
+	}
+
+	dbConn, importUseCase := connect()
+	defer db.CloseDB(dbConn)
+
+	summary := discordSummary{unmapped: map[string]int{}}
+	exportDir := filepath.Dir(*exportPath)
+	for _, msg := range export.Messages {
+		var attachments []discordAttachment
+		for _, a := range msg.Attachments {
+			if imaging.SupportedExtension(a.FileName) {
+				attachments = append(attachments, a)
+			}
+		}
+		if len(attachments) == 0 {
+			summary.withoutImage++
+			continue
+		}
+
+		username := users.lookup(msg.Author)
+		if username == "" {
+			summary.unmapped[msg.Author.tag()]++
+			continue
+		}
+
+		imported, err := importUseCase.IsImported(domain.ImportSourceDiscord, msg.ID)
+		if err != nil {
+			return err
+		}
+		if imported {
+			summary.skipped++
+			continue
+		}
+
+		files := make([]string, 0, len(attachments))
+		for _, a := range attachments {
+			file, err := attachmentPath(a, exportDir, *mediaDir)
+			if err != nil {
+				break
+			}
+			files = append(files, file)
+		}
+		if len(files) < len(attachments) {
+			fmt.Printf("FAILED  %s: attachment not found in %s\n", msg.ID, *mediaDir)
+			summary.failed++
+			continue
+		}
+
+		req := domain.ImportPost{
+			Source:      domain.ImportSourceDiscord,
+			ExternalID:  msg.ID,
+			Username:    username,
+			Title:       discordTitle(msg, export.Channel.Name),
+			Description: strings.TrimSpace(msg.Content),
+			Tags:        *tags,
+			Visibility:  *visibility,
+			PostedAt:    msg.Timestamp,
+			Files:       files,
+		}
+		if *dryRun {
+			fmt.Printf("IMPORT  %s: %q by %s, %d images\n", msg.ID, req.Title, username, len(files))
+			summary.imported++
+			continue
+		}
+
+		post, err := importUseCase.ImportPost(req)
+		switch {
+		case errors.Is(err, domain.ErrConflict):
+			summary.skipped++
+		case err != nil:
+			fmt.Printf("FAILED  %s: %v\n", msg.ID, err)
+			summary.failed++
+		default:
+			fmt.Printf("IMPORT  %s: post %d by %s, %d images\n", msg.ID, post.ID, username, len(files))
+			summary.imported++
+		}
+	}
+
+	summary.print()
+	if summary.failed > 0 {
+		return fmt.Errorf("%d messages failed to import", summary.failed)
+	}
+	return nil
+}
+
+// readDiscordExport reads a DiscordChatExporter JSON export
+func readDiscordExport(name string) (*discordExport, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var export discordExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%s is not a DiscordChatExporter JSON export: %w", name, err)
+	}
+	return &export, nil
+}
+
+// userMapping maps Discord accounts to usernames
+type userMapping map[string]string
+
+// readUserMapping reads the mapping file
+func readUserMapping(name string) (userMapping, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var users userMapping
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of Discord accounts to usernames: %w", name, err)
+	}
+	return users, nil
+}
+
+// lookup returns the username of an author, preferring the account ID which survives renames
+func (m userMapping) lookup(author discordAuthor) string {
+	for _, key := range []string{author.ID, author.tag(), author.Name} {
+		if username := m[key]; key != "" && username != "" {
+			return username
+		}
+	}
+	return ""
+}
+
+// attachmentPath finds the downloaded file of an attachment. Exports made with --media point
+// at the file relative to the export; otherwise it is looked up in the media directory by name
+func attachmentPath(a discordAttachment, exportDir, mediaDir string) (string, error) {
+	var candidates []string
+	if u, err := url.Parse(a.URL); err == nil && u.Path != "" {
+		if u.Scheme == "" {
+			local := filepath.FromSlash(u.Path)
+			if !filepath.IsAbs(local) {
+				local = filepath.Join(exportDir, local)
+			}
+			candidates = append(candidates, local)
+		}
+		candidates = append(candidates, filepath.Join(mediaDir, path.Base(u.Path)))
+	}
+	candidates = append(candidates, filepath.Join(mediaDir, filepath.Base(a.FileName)))
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// discordTitle uses the first line of the message, or the channel and date for messages without text
+func discordTitle(msg discordMessage, channel string) string {
+	for _, line := range strings.Split(msg.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxDiscordTitleLen {
+			line = string([]rune(line)[:maxDiscordTitleLen-1]) + "…"
+		}
+		return line
+	}
+	return fmt.Sprintf("#%s %s", channel, msg.Timestamp.Format("2006-01-02"))
+}
+
+// print prints the counts and the authors that have no user to import as
+func (s *discordSummary) print() {
+	unmapped := 0
+	authors := make([]string, 0, len(s.unmapped))
+	for author, count := range s.unmapped {
+		unmapped += count
+		authors = append(authors, fmt.Sprintf("%s (%d)", author, count))
+	}
+	sort.Strings(authors)
+
+	fmt.Println()
+	fmt.Printf("Imported:          %d\n", s.imported)
+	fmt.Printf("Already imported:  %d\n", s.skipped)
+	fmt.Printf("Without images:    %d\n", s.withoutImage)
+	fmt.Printf("Unmapped authors:  %d\n", unmapped)
+	for _, author := range authors {
+		fmt.Printf("  %s\n", author)
+	}
+	fmt.Printf("Failed:            %d\n", s.failed)
+}
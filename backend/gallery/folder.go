@@ -0,0 +1,298 @@
+// folder.go
+
+package main
+
+import (
+	"backend/domain"
+	"backend/infrastructure/db"
+	"backend/infrastructure/imaging"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultStateFile is created in the imported directory unless -state is given
+const defaultStateFile = ".gallery-import-state"
+
+// folderResult is the outcome of importing one file
+type folderResult struct {
+	file    string // path relative to the imported directory, with forward slashes
+	imageID uint
+	err     error
+}
+
+// stateEntry is one line of the state file
+type stateEntry struct {
+	File       string    `json:"file"`
+	ImageID    uint      `json:"image_id"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// importState remembers which files have been imported. Each success is appended as a
+// JSON line straight away, so an interrupted run resumes where it stopped
+type importState struct {
+	file *os.File
+	done map[string]bool
+}
+
+// importFolder uploads every image under a directory, reading metadata from sidecar files
+func importFolder(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dir := flags.String("dir", "", "取り込むディレクトリ（必須）。サブディレクトリも含める")
+	username := flags.String("user", "", "サイドカーにauthorがない画像をアップロードするユーザー名")
+	statePath := flags.String("state", "", "取り込み済みのファイルを記録するファイル（デフォルトは<dir>/"+defaultStateFile+"）")
+	tags := flags.String("tags", "", "すべての画像に付けるタグ（カンマ区切り）")
+	visibility := flags.String("visibility", domain.VisibilityPublic, "取り込んだ画像の公開範囲")
+	concurrency := flags.Int("concurrency", 4, "同時にアップロードする数")
+	dryRun := flags.Bool("dry-run", false, "取り込まずに対象のファイルとサイドカーの内容を表示")
+	flags.Parse(args)
+
+	if *dir == "" || *concurrency < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(*dir, defaultStateFile)
+	}
+
+	files, err := collectImages(*dir)
+	if err != nil {
+		return err
+	}
+	state, err := openImportState(*statePath)
+	if err != nil {
+		return err
+	}
+	defer state.Close()
+
+	pending := make([]string, 0, len(files))
+	for _, file := range files {
+		if !state.done[file] {
+			pending = append(pending, file)
+		}
+	}
+
+	build := func(file string) (domain.ImportImage, error) {
+		return folderRequest(*dir, file, *username, *tags, *visibility)
+	}
+
+	var results []folderResult
+	if *dryRun {
+		for _, file := range pending {
+			req, err := build(file)
+			if err == nil {
+				fmt.Printf("IMPORT  %s: %q by %s, tags %q, date %s\n", file, req.Title, req.Username, req.Tags, formatDate(req.CreatedAt))
+			}
+			results = append(results, folderResult{file: file, err: err})
+		}
+	} else {
+		dbConn, importUseCase := connect()
+		defer db.CloseDB(dbConn)
+
+		// Results are handled on this goroutine only, so the state file needs no locking
+		for result := range uploadFolder(importUseCase, pending, build, *concurrency) {
+			if result.err == nil {
+				if err := state.record(result.file, result.imageID); err != nil {
+					return fmt.Errorf("failed to update %s: %w", *statePath, err)
+				}
+				fmt.Printf("IMPORT  %s: image %d\n", result.file, result.imageID)
+			}
+			results = append(results, result)
+		}
+	}
+
+	failed := printFolderSummary(len(files), len(files)-len(pending), results)
+	if failed > 0 {
+		return fmt.Errorf("%d files failed to import; run the command again to retry them", failed)
+	}
+	return nil
+}
+
+// uploadFolder imports the files with at most concurrency uploads in flight
+func uploadFolder(importUseCase domain.ImportUseCase, files []string, build func(string) (domain.ImportImage, error), concurrency int) <-chan folderResult {
+	jobs := make(chan string)
+	results := make(chan folderResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				req, err := build(file)
+				if err != nil {
+					results <- folderResult{file: file, err: err}
+					continue
+				}
+				image, err := importUseCase.ImportImage(req)
+				if err != nil {
+					results <- folderResult{file: file, err: err}
+					continue
+				}
+				results <- folderResult{file: file, imageID: image.ID}
+			}
+		}()
+	}
+
+	go func() {
+		for _, file := range files {
+			jobs <- file
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// collectImages lists the images under dir in a stable order, skipping hidden files and directories
+func collectImages(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !imaging.SupportedExtension(entry.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// folderRequest builds the upload of a file from its sidecar and the command's defaults
+func folderRequest(dir, file, username, tags, visibility string) (domain.ImportImage, error) {
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	meta, err := readSidecar(path)
+	if err != nil {
+		return domain.ImportImage{}, err
+	}
+
+	createdAt, err := meta.date()
+	if err != nil {
+		return domain.ImportImage{}, err
+	}
+
+	req := domain.ImportImage{
+		Username:    strings.TrimSpace(meta.Author),
+		Title:       strings.TrimSpace(meta.Title),
+		Description: strings.TrimSpace(meta.Description),
+		Tags:        meta.tags(tags),
+		Visibility:  visibility,
+		CreatedAt:   createdAt,
+		File:        path,
+	}
+	if req.Username == "" {
+		req.Username = username
+	}
+	if req.Username == "" {
+		return domain.ImportImage{}, errors.New("no author in the sidecar and no -user given")
+	}
+	if req.Title == "" {
+		name := filepath.Base(path)
+		req.Title = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return req, nil
+}
+
+// openImportState reads the files recorded by earlier runs and opens the state file for appending
+func openImportState(name string) (*importState, error) {
+	data, err := os.ReadFile(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	state := &importState{done: map[string]bool{}}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var entry stateEntry
+		// A line cut short by an interrupted run is ignored and its file imported again
+		if err := json.Unmarshal(line, &entry); err == nil && entry.File != "" {
+			state.done[entry.File] = true
+		}
+	}
+
+	state.file, err = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	// Start on a fresh line after a cut-short one
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := state.file.Write([]byte("\n")); err != nil {
+			state.file.Close()
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// record marks a file as imported
+func (s *importState) record(file string, imageID uint) error {
+	line, err := json.Marshal(stateEntry{File: file, ImageID: imageID, ImportedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	s.done[file] = true
+	return nil
+}
+
+// Close closes the state file
+func (s *importState) Close() error {
+	return s.file.Close()
+}
+
+// formatDate prints a sidecar date, or that the upload time will be kept
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "(upload time)"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// printFolderSummary prints the counts and every failure, and returns the number of failures
+func printFolderSummary(found, resumed int, results []folderResult) int {
+	imported := 0
+	var failures []folderResult
+	for _, result := range results {
+		if result.err != nil {
+			failures = append(failures, result)
+		} else {
+			imported++
+		}
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].file < failures[j].file })
+
+	fmt.Println()
+	fmt.Printf("Images found:      %d\n", found)
+	fmt.Printf("Already imported:  %d\n", resumed)
+	fmt.Printf("Imported:          %d\n", imported)
+	fmt.Printf("Failed:            %d\n", len(failures))
+	for _, failure := range failures {
+		fmt.Printf("  %s: %v\n", failure.file, failure.err)
+	}
+	return len(failures)
+}
@@ -0,0 +1,64 @@
+// gallery.go
+
+package main
+
+import (
+	"backend/domain"
+	"backend/infrastructure/db"
+	"backend/repository"
+	"backend/usecase"
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const usage = `Usage: go run ./gallery <command> [options]
+
+Commands:
+  import          ディレクトリ内の画像をサイドカー（JSON/YAML）のメタデータ付きで取り込む
+  import-discord  DiscordChatExporterで書き出したJSONと添付ファイルから投稿を取り込む
+
+各コマンドのオプションは go run ./gallery <command> -h で表示`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = importFolder(os.Args[2:])
+	case "import-discord":
+		err = importDiscord(os.Args[2:])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// connect opens the database and wires the import use case the same way the server does
+func connect() (*gorm.DB, domain.ImportUseCase) {
+	dbConn := db.ConnectDB()
+	// Logging every statement would bury the import's own output
+	dbConn.Logger = logger.Default.LogMode(logger.Warn)
+
+	userRepo := repository.NewUserRepository(dbConn)
+	imageRepo := repository.NewImageRepository(dbConn)
+	blobRepo := repository.NewBlobRepository(dbConn)
+	duplicateRepo := repository.NewDuplicateFlagRepository(dbConn)
+	outboxRepo := repository.NewOutboxRepository(dbConn)
+	bookmarkRepo := repository.NewBookmarkRepository(dbConn)
+	importRepo := repository.NewImportRepository(dbConn)
+
+	imageUseCase := usecase.NewImageUseCase(imageRepo, blobRepo, duplicateRepo, outboxRepo, bookmarkRepo)
+	return dbConn, usecase.NewImportUseCase(importRepo, userRepo, imageUseCase)
+}
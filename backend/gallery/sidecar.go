@@ -0,0 +1,163 @@
+// sidecar.go
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sidecarExtensions are tried in order after both "<file>" and "<file without extension>"
+var sidecarExtensions = []string{".json", ".yaml", ".yml"}
+
+// dateLayouts are the accepted forms of a sidecar's date; archives often only know the month or year
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// sidecar is the optional metadata stored next to an image, e.g. zine-01.png.yaml or zine-01.json
+type sidecar struct {
+	Title       string        `json:"title" yaml:"title"`
+	Description string        `json:"description" yaml:"description"`
+	Tags        sidecarTags   `json:"tags" yaml:"tags"`
+	Author      string        `json:"author" yaml:"author"` // username to upload as
+	Date        sidecarScalar `json:"date" yaml:"date"`
+	Rating      sidecarScalar `json:"rating" yaml:"rating"` // stored as a "rating:<value>" tag
+}
+
+// sidecarTags accepts either a list or a comma-separated string
+type sidecarTags []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *sidecarTags) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*t = list
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("tags must be a list or a comma-separated string")
+	}
+	*t = strings.Split(s, ",")
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (t *sidecarTags) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*t = list
+		return nil
+	}
+
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return errors.New("tags must be a list or a comma-separated string")
+	}
+	*t = strings.Split(s, ",")
+	return nil
+}
+
+// sidecarScalar accepts a string or a number, so that `rating: 4` and `date: 1998` work in JSON too
+type sidecarScalar string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *sidecarScalar) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+	case string:
+		*s = sidecarScalar(v)
+	case float64, bool:
+		*s = sidecarScalar(strings.TrimSpace(string(data)))
+	default:
+		return errors.New("must be a string or a number")
+	}
+	return nil
+}
+
+// readSidecar reads the sidecar of an image; images without one get empty metadata
+func readSidecar(imagePath string) (*sidecar, error) {
+	bases := []string{imagePath, strings.TrimSuffix(imagePath, filepath.Ext(imagePath))}
+	for _, base := range bases {
+		for _, ext := range sidecarExtensions {
+			name := base + ext
+			data, err := os.ReadFile(name)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			var meta sidecar
+			if ext == ".json" {
+				err = json.Unmarshal(data, &meta)
+			} else {
+				err = yaml.Unmarshal(data, &meta)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filepath.Base(name), err)
+			}
+			return &meta, nil
+		}
+	}
+	return &sidecar{}, nil
+}
+
+// date parses the sidecar's date; dates without a zone are taken as local time
+func (s *sidecar) date() (time.Time, error) {
+	value := strings.TrimSpace(string(s.Date))
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
+
+// tags merges the default tags with the sidecar's tags and rating, dropping blanks and repeats
+func (s *sidecar) tags(defaults string) string {
+	all := append(strings.Split(defaults, ","), s.Tags...)
+	if rating := strings.TrimSpace(string(s.Rating)); rating != "" {
+		all = append(all, "rating:"+rating)
+	}
+
+	seen := make(map[string]bool, len(all))
+	tags := make([]string, 0, len(all))
+	for _, tag := range all {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return strings.Join(tags, ",")
+}
@@ -9,16 +9,27 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
+// downloadTimeout
+// @description: 元画像のダウンロードにかける時間の上限（応答が止まったときに待ち続けない）
+const downloadTimeout = time.Minute
+
 // Service
 // @description: Cloudinaryサービスの実装
 type Service struct {
-	cld *cloudinary.Cloudinary
+	cld        *cloudinary.Cloudinary
+	httpClient *http.Client // 元画像のダウンロード用
 }
 
 // NewService
@@ -37,15 +48,18 @@ func NewService() (*Service, error) {
 		return nil, fmt.Errorf("failed to initialize Cloudinary: %w", err)
 	}
 
-	return &Service{cld: cld}, nil
+	return &Service{
+		cld:        cld,
+		httpClient: &http.Client{Timeout: downloadTimeout},
+	}, nil
 }
 
 // UploadImage
-// @description: 画像をCloudinaryにアップロード
-func (s *Service) UploadImage(ctx context.Context, imageData io.Reader, filename string, folder string) (*uploader.UploadResult, error) {
+// @description: 画像をCloudinaryにアップロード（folder/publicIDに同じ画像があれば上書き）
+func (s *Service) UploadImage(ctx context.Context, imageData io.Reader, publicID string, folder string) (*uploader.UploadResult, error) {
 	uploadParams := uploader.UploadParams{
 		Folder:       folder,
-		PublicID:     filename,
+		PublicID:     publicID,
 		ResourceType: "image",
 	}
 
@@ -53,24 +67,81 @@ func (s *Service) UploadImage(ctx context.Context, imageData io.Reader, filename
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload image: %w", err)
 	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("failed to upload image: %s", result.Error.Message)
+	}
 
 	return result, nil
 }
 
+// RenameImage
+// @description: 画像のPublic IDを変更し、新しいURLを返す（変更先に同じ画像があれば上書き）
+func (s *Service) RenameImage(ctx context.Context, fromPublicID, toPublicID string) (string, error) {
+	overwrite := true
+	result, err := s.cld.Upload.Rename(ctx, uploader.RenameParams{
+		FromPublicID: fromPublicID,
+		ToPublicID:   toPublicID,
+		ResourceType: "image",
+		Overwrite:    &overwrite,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to rename image: %w", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to rename image: %v", result.Error)
+	}
+
+	return result.SecureURL, nil
+}
+
 // DeleteImage
-// @description: 画像をCloudinaryから削除
+// @description: 画像をCloudinaryから削除（存在しない場合も成功として扱う）
 func (s *Service) DeleteImage(ctx context.Context, publicID string) error {
-	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+	result, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
 		PublicID:     publicID,
 		ResourceType: "image",
 	})
 	if err != nil {
 		return fmt.Errorf("failed to delete image: %w", err)
 	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete image: %s", result.Error.Message)
+	}
 
 	return nil
 }
 
+// StoredImage
+// @description: Cloudinaryに保存されている画像
+type StoredImage struct {
+	PublicID  string
+	CreatedAt time.Time
+}
+
+// ListImages
+// @description: Public IDがprefixで始まる画像を1ページ分取得し、次のページのカーソルを返す（最後のページでは空）
+func (s *Service) ListImages(ctx context.Context, prefix, cursor string) ([]StoredImage, string, error) {
+	result, err := s.cld.Admin.Assets(ctx, admin.AssetsParams{
+		AssetType:    api.Image,
+		DeliveryType: "upload",
+		Prefix:       prefix,
+		NextCursor:   cursor,
+		MaxResults:   500,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list images: %w", err)
+	}
+	if result.Error.Message != "" {
+		return nil, "", fmt.Errorf("failed to list images: %s", result.Error.Message)
+	}
+
+	images := make([]StoredImage, 0, len(result.Assets))
+	for _, asset := range result.Assets {
+		images = append(images, StoredImage{PublicID: asset.PublicID, CreatedAt: asset.CreatedAt})
+	}
+	return images, result.NextCursor, nil
+}
+
 // GetImageURL
 // @description: 画像のCloudinaryURLを生成
 func (s *Service) GetImageURL(publicID string, transformations map[string]interface{}) string {
@@ -78,16 +149,60 @@ func (s *Service) GetImageURL(publicID string, transformations map[string]interf
 	if err != nil {
 		return ""
 	}
-	return img.Config.Cloud.APIKey
+
+	// キーでソートして "c_fill,h_100,w_100" 形式のトランスフォーメーションにする
+	keys := make([]string, 0, len(transformations))
+	for key := range transformations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s_%v", key, transformations[key]))
+	}
+	img.Transformation = strings.Join(parts, ",")
+
+	url, err := img.String()
+	if err != nil {
+		return ""
+	}
+	return url
 }
 
 // TransformImage
 // @description: 画像を変換したURLを生成
 func (s *Service) TransformImage(publicID string, width, height int, crop string) string {
-	img, err := s.cld.Image(publicID)
+	transformations := map[string]interface{}{}
+	if width > 0 {
+		transformations["w"] = width
+	}
+	if height > 0 {
+		transformations["h"] = height
+	}
+	if crop != "" {
+		transformations["c"] = crop
+	}
+
+	return s.GetImageURL(publicID, transformations)
+}
+
+// DownloadImage
+// @description: アップロード済み画像の元データを取得
+func (s *Service) DownloadImage(ctx context.Context, secureURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secureURL, nil)
 	if err != nil {
-		return ""
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
 	}
 
-	return img.AssetType.String()
+	return resp.Body, nil
 }
@@ -6,16 +6,26 @@ import (
 	"os"
 	"time"
 
-	"backend/domain"
-
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// ConnectDB establishes database connection
+// ConnectDB establishes database connection and refuses to start on an unmigrated schema
 func ConnectDB() *gorm.DB {
+	db := OpenDB()
+
+	if err := RequireMigrated(db); err != nil {
+		log.Fatalln("Database schema is out of date, run `go run ./migrate up` first:", err)
+	}
+
+	fmt.Println("Database connected successfully")
+	return db
+}
+
+// OpenDB establishes database connection without checking migrations
+func OpenDB() *gorm.DB {
 	// Load environment variables
 	if os.Getenv("GO_ENV") == "dev" {
 		err := godotenv.Load()
@@ -51,25 +61,9 @@ func ConnectDB() *gorm.DB {
 	sqlDB.SetMaxIdleConns(5)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Auto migrate database schema
-	err = AutoMigrate(db)
-	if err != nil {
-		log.Fatalln("Failed to migrate database:", err)
-	}
-
-	fmt.Println("Database connected successfully")
 	return db
 }
 
-// AutoMigrate runs database migrations
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&domain.User{},
-		&domain.Image{},
-		&domain.Post{},
-	)
-}
-
 // CloseDB closes database connection
 func CloseDB(db *gorm.DB) {
 	sqlDB, err := db.DB()
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS post_images;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS images;
+DROP TABLE IF EXISTS users;
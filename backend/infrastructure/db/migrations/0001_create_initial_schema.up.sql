@@ -0,0 +1,62 @@
+-- 既存のAutoMigrate済みDBでもそのまま適用できるようにIF NOT EXISTSを付ける
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL,
+    username TEXT NOT NULL,
+    password TEXT NOT NULL,
+    first_name TEXT,
+    last_name TEXT,
+    avatar TEXT,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS images (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    cloudinary_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    width BIGINT,
+    height BIGINT,
+    file_size BIGINT,
+    format TEXT,
+    tags TEXT,
+    is_public BOOLEAN DEFAULT TRUE,
+    view_count BIGINT DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_images_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_images_deleted_at ON images (deleted_at);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    tags TEXT,
+    is_public BOOLEAN DEFAULT TRUE,
+    view_count BIGINT DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_posts_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS post_images (
+    post_id BIGINT NOT NULL,
+    image_id BIGINT NOT NULL,
+    PRIMARY KEY (post_id, image_id),
+    CONSTRAINT fk_post_images_post FOREIGN KEY (post_id) REFERENCES posts (id),
+    CONSTRAINT fk_post_images_image FOREIGN KEY (image_id) REFERENCES images (id)
+);
@@ -0,0 +1 @@
+ALTER TABLE images ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_images_sha256;
+ALTER TABLE images DROP COLUMN IF EXISTS sha256;
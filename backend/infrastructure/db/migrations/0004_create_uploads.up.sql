@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS uploads (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    filename TEXT NOT NULL,
+    title TEXT,
+    description TEXT,
+    tags TEXT,
+    image_id BIGINT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_uploads_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_uploads_image FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_uploads_user_id ON uploads (user_id);
+CREATE INDEX IF NOT EXISTS idx_uploads_expires_at ON uploads (expires_at);
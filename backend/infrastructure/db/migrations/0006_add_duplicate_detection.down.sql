@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS duplicate_flags;
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
+ALTER TABLE images DROP COLUMN IF EXISTS phash;
@@ -0,0 +1,21 @@
+ALTER TABLE images ADD COLUMN IF NOT EXISTS phash BIGINT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS duplicate_flags (
+    id BIGSERIAL PRIMARY KEY,
+    image_id BIGINT NOT NULL,
+    matched_image_id BIGINT NOT NULL,
+    distance INTEGER NOT NULL,
+    exact BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    reviewed_by BIGINT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_duplicate_flags_image FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE,
+    CONSTRAINT fk_duplicate_flags_matched_image FOREIGN KEY (matched_image_id) REFERENCES images (id) ON DELETE CASCADE,
+    CONSTRAINT fk_duplicate_flags_reviewer FOREIGN KEY (reviewed_by) REFERENCES users (id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_duplicate_flags_image_id ON duplicate_flags (image_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_flags_matched_image_id ON duplicate_flags (matched_image_id);
+CREATE INDEX IF NOT EXISTS idx_duplicate_flags_status ON duplicate_flags (status);
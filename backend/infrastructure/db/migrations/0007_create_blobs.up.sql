@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS blobs (
+    sha256 VARCHAR(64) PRIMARY KEY,
+    cloudinary_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    file_size BIGINT,
+    format TEXT,
+    variants JSONB NOT NULL DEFAULT '{}',
+    ref_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+-- Existing images with a known hash share the file of the oldest image with the same content.
+-- The other copies are no longer referenced by any row and can be removed from storage.
+INSERT INTO blobs (sha256, cloudinary_id, url, width, height, file_size, format, variants, ref_count, created_at, updated_at)
+SELECT DISTINCT ON (sha256)
+    sha256, cloudinary_id, url, width, height, file_size, format, variants,
+    COUNT(*) OVER (PARTITION BY sha256), NOW(), NOW()
+FROM images
+WHERE sha256 IS NOT NULL AND sha256 <> '' AND deleted_at IS NULL
+ORDER BY sha256, id
+ON CONFLICT (sha256) DO NOTHING;
+
+UPDATE images
+SET cloudinary_id = blobs.cloudinary_id, url = blobs.url, variants = blobs.variants
+FROM blobs
+WHERE images.sha256 = blobs.sha256 AND images.deleted_at IS NULL;
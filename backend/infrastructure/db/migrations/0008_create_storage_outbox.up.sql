@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS storage_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    action TEXT NOT NULL,
+    public_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_storage_outbox_next_attempt_at ON storage_outbox (next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+-- The deleted rows cannot be restored.
+SELECT 1;
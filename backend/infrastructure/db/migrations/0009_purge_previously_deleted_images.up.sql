@@ -0,0 +1,4 @@
+-- Images deleted before the trash bin existed already had their files removed (or their blob released).
+-- Remove the rows so that the trash purge job does not release them a second time.
+DELETE FROM post_images WHERE image_id IN (SELECT id FROM images WHERE deleted_at IS NOT NULL);
+DELETE FROM images WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS image_revisions;
+ALTER TABLE images DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,26 @@
+ALTER TABLE images ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS image_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    image_id BIGINT NOT NULL,
+    revision INTEGER NOT NULL,
+    cloudinary_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    width INTEGER,
+    height INTEGER,
+    file_size BIGINT,
+    format TEXT,
+    sha256 TEXT,
+    phash BIGINT,
+    variants JSONB NOT NULL DEFAULT '{}',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_image_revisions_image FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_image_revisions_image_revision ON image_revisions (image_id, revision);
+
+-- The current file of every image becomes its first revision, taking over the image's blob reference
+INSERT INTO image_revisions (image_id, revision, cloudinary_id, url, width, height, file_size, format, sha256, phash, variants, metadata, created_at)
+SELECT id, 1, cloudinary_id, url, width, height, file_size, format, sha256, phash, variants, metadata, created_at
+FROM images
+ON CONFLICT DO NOTHING;
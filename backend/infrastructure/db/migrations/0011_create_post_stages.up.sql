@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS post_stages (
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL,
+    position INTEGER NOT NULL,
+    image_id BIGINT NOT NULL,
+    label TEXT NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_post_stages_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_post_stages_image FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_stages_post_position ON post_stages (post_id, position);
+CREATE INDEX IF NOT EXISTS idx_post_stages_image_id ON post_stages (image_id);
@@ -0,0 +1,7 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+
+-- Existing posts were published when they were created
+UPDATE posts SET publish_at = created_at WHERE publish_at IS NULL AND status = 'published';
+
+CREATE INDEX IF NOT EXISTS idx_posts_status_publish_at ON posts (status, publish_at);
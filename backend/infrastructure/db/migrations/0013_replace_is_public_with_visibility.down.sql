@@ -0,0 +1,10 @@
+-- Only public works stay public; members-only and unlisted works become private
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS is_public BOOLEAN DEFAULT TRUE;
+UPDATE posts SET is_public = (visibility = 'public');
+DROP INDEX IF EXISTS idx_posts_visibility;
+ALTER TABLE posts DROP COLUMN IF EXISTS visibility;
+
+ALTER TABLE images ADD COLUMN IF NOT EXISTS is_public BOOLEAN DEFAULT TRUE;
+UPDATE images SET is_public = (visibility = 'public');
+DROP INDEX IF EXISTS idx_images_visibility;
+ALTER TABLE images DROP COLUMN IF EXISTS visibility;
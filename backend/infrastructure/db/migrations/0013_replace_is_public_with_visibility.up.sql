@@ -0,0 +1,9 @@
+ALTER TABLE images ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public';
+UPDATE images SET visibility = 'private' WHERE is_public = FALSE;
+ALTER TABLE images DROP COLUMN IF EXISTS is_public;
+CREATE INDEX IF NOT EXISTS idx_images_visibility ON images (visibility);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public';
+UPDATE posts SET visibility = 'private' WHERE is_public = FALSE;
+ALTER TABLE posts DROP COLUMN IF EXISTS is_public;
+CREATE INDEX IF NOT EXISTS idx_posts_visibility ON posts (visibility);
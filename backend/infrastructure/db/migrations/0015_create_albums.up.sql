@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS albums (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    visibility TEXT NOT NULL DEFAULT 'public',
+    cover_image_id BIGINT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_albums_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_albums_cover_image FOREIGN KEY (cover_image_id) REFERENCES images (id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_albums_user_id ON albums (user_id);
+
+CREATE TABLE IF NOT EXISTS album_items (
+    id BIGSERIAL PRIMARY KEY,
+    album_id BIGINT NOT NULL,
+    position INTEGER NOT NULL,
+    item_type TEXT NOT NULL,
+    image_id BIGINT,
+    post_id BIGINT,
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_album_items_album FOREIGN KEY (album_id) REFERENCES albums (id) ON DELETE CASCADE,
+    CONSTRAINT fk_album_items_image FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE,
+    CONSTRAINT fk_album_items_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT chk_album_items_target CHECK (
+        (item_type = 'image' AND image_id IS NOT NULL AND post_id IS NULL) OR
+        (item_type = 'post' AND post_id IS NOT NULL AND image_id IS NULL)
+    )
+);
+CREATE INDEX IF NOT EXISTS idx_album_items_album_position ON album_items (album_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_items_album_image ON album_items (album_id, image_id) WHERE image_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_items_album_post ON album_items (album_id, post_id) WHERE post_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_album_items_image_id ON album_items (image_id);
+CREATE INDEX IF NOT EXISTS idx_album_items_post_id ON album_items (post_id);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS bookmark_folders (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_bookmark_folders_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmark_folders_user_name ON bookmark_folders (user_id, name);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    item_type TEXT NOT NULL,
+    image_id BIGINT,
+    post_id BIGINT,
+    folder_id BIGINT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_bookmarks_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_bookmarks_image FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE,
+    CONSTRAINT fk_bookmarks_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_bookmarks_folder FOREIGN KEY (folder_id) REFERENCES bookmark_folders (id) ON DELETE SET NULL,
+    CONSTRAINT chk_bookmarks_target CHECK (
+        (item_type = 'image' AND image_id IS NOT NULL AND post_id IS NULL) OR
+        (item_type = 'post' AND post_id IS NOT NULL AND image_id IS NULL)
+    )
+);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created_at ON bookmarks (user_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_image ON bookmarks (user_id, image_id) WHERE image_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_post ON bookmarks (user_id, post_id) WHERE post_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bookmarks_image_id ON bookmarks (image_id);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_post_id ON bookmarks (post_id);
+CREATE INDEX IF NOT EXISTS idx_bookmarks_folder_id ON bookmarks (folder_id);
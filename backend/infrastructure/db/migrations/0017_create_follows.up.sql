@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id BIGINT NOT NULL,
+    followee_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT fk_follows_follower FOREIGN KEY (follower_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_follows_followee FOREIGN KEY (followee_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT chk_follows_not_self CHECK (follower_id <> followee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_follows_followee_created_at ON follows (followee_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_follows_follower_created_at ON follows (follower_id, created_at);
+
+-- The feed walks each followed user's posts by publish time
+CREATE INDEX IF NOT EXISTS idx_posts_user_publish_at ON posts (user_id, publish_at DESC, id DESC);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    actor_id BIGINT,
+    actor_count INTEGER NOT NULL DEFAULT 0,
+    target_type TEXT NOT NULL,
+    target_id BIGINT NOT NULL,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_updated_at ON notifications (user_id, updated_at);
+-- Unread notifications of the same kind about the same target are aggregated into one row
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_unread_target ON notifications (user_id, type, target_type, target_id) WHERE read_at IS NULL;
+
+-- Distinct users aggregated into a notification, so repeated actions are counted once
+CREATE TABLE IF NOT EXISTS notification_actors (
+    notification_id BIGINT NOT NULL,
+    actor_id BIGINT NOT NULL,
+    PRIMARY KEY (notification_id, actor_id),
+    CONSTRAINT fk_notification_actors_notification FOREIGN KEY (notification_id) REFERENCES notifications (id) ON DELETE CASCADE,
+    CONSTRAINT fk_notification_actors_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type),
+    CONSTRAINT fk_notification_preferences_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
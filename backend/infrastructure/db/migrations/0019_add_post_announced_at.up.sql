@@ -0,0 +1,4 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS announced_at TIMESTAMPTZ;
+
+-- Posts that are already public must not be announced again on their next edit
+UPDATE posts SET announced_at = publish_at WHERE status = 'published' AND visibility = 'public';
@@ -0,0 +1,12 @@
+-- Records posts brought in from other services so that re-running an import skips them
+CREATE TABLE IF NOT EXISTS imported_items (
+    id BIGSERIAL PRIMARY KEY,
+    source TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    user_id BIGINT NOT NULL,
+    post_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ,
+    CONSTRAINT fk_imported_items_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_imported_items_post FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_imported_items_source_external_id ON imported_items (source, external_id);
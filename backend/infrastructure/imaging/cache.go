@@ -0,0 +1,185 @@
+package imaging
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultCacheMaxBytes
+// @description: 変換キャッシュの上限（IMAGE_CACHE_MAX_BYTESで上書き可能）
+const defaultCacheMaxBytes = 512 * 1024 * 1024
+
+// DiskCache
+// @description: 変換済み画像をディスクに保存するLRUキャッシュ
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	order   *list.List // 先頭が最近使われたもの
+	entries map[string]*list.Element
+	size    int64
+}
+
+// cacheEntry
+// @description: キャッシュファイルのキーとサイズ
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+// NewDiskCacheFromEnv
+// @description: 環境変数の設定でキャッシュを初期化
+func NewDiskCacheFromEnv() (*DiskCache, error) {
+	dir := os.Getenv("IMAGE_CACHE_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "image-gallery-cache")
+	}
+
+	maxBytes := int64(defaultCacheMaxBytes)
+	if env := os.Getenv("IMAGE_CACHE_MAX_BYTES"); env != "" {
+		n, err := strconv.ParseInt(env, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid IMAGE_CACHE_MAX_BYTES: %q", env)
+		}
+		maxBytes = n
+	}
+
+	return NewDiskCache(dir, maxBytes)
+}
+
+// NewDiskCache
+// @description: 既存のキャッシュファイルを更新日時順に読み込んで初期化
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type existing struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var found []existing
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil || !info.Mode().IsRegular() || filepath.Ext(f.Name()) == ".tmp" {
+			continue
+		}
+		found = append(found, existing{key: f.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.Before(found[j].modTime) })
+
+	for _, f := range found {
+		c.entries[f.key] = c.order.PushFront(&cacheEntry{key: f.key, size: f.size})
+		c.size += f.size
+	}
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+// Get
+// @description: キャッシュから読み込み、最近使ったものとして記録
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	// 再起動後もLRU順を復元できるように更新日時を更新
+	now := time.Now()
+	os.Chtimes(c.path(key), now, now)
+
+	return data, true
+}
+
+// Put
+// @description: キャッシュに書き込み、上限を超えた分を古い順に削除
+func (c *DiskCache) Put(key string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store cache file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		c.size -= entry.size
+		entry.size = int64(len(data))
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(&cacheEntry{key: key, size: int64(len(data))})
+	}
+	c.size += int64(len(data))
+	c.evict()
+
+	return nil
+}
+
+// evict
+// @description: 上限に収まるまで最も古いエントリを削除（ロックを保持して呼ぶ）
+func (c *DiskCache) evict() {
+	for c.size > c.maxBytes {
+		elem := c.order.Back()
+		if elem == nil {
+			return
+		}
+		entry := elem.Value.(*cacheEntry)
+		c.order.Remove(elem)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+		os.Remove(c.path(entry.key))
+	}
+}
+
+// path
+// @description: キーに対応するファイルパス
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
@@ -0,0 +1,139 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultVariantSizes
+// @description: 派生画像の長辺サイズ（IMAGE_VARIANT_SIZESで上書き可能）
+var defaultVariantSizes = []int{256, 768, 1600}
+
+// Derivative
+// @description: 生成された派生画像
+type Derivative struct {
+	Name   string
+	Width  int
+	Height int
+	Format string
+	Data   []byte
+	SameAs string // 別の派生画像と同じものを使う場合はその名前（Dataは空）
+}
+
+// Generator
+// @description: 設定に従って派生画像を生成する
+type Generator struct {
+	sizes  []int
+	format string
+}
+
+// NewGenerator
+// @description: 環境変数から派生画像の設定を読み込む
+func NewGenerator() (*Generator, error) {
+	sizes := defaultVariantSizes
+	if env := os.Getenv("IMAGE_VARIANT_SIZES"); env != "" {
+		sizes = nil
+		for _, s := range strings.Split(env, ",") {
+			size, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("invalid IMAGE_VARIANT_SIZES: %q", env)
+			}
+			sizes = append(sizes, size)
+		}
+	}
+
+	// WebPのエンコーダは可逆圧縮のみで、写真ではJPEGより大きくなる
+	format := os.Getenv("IMAGE_VARIANT_FORMAT")
+	if format == "" {
+		format = "jpeg"
+	}
+	if format != "webp" && format != "jpeg" {
+		return nil, fmt.Errorf("invalid IMAGE_VARIANT_FORMAT: %q", format)
+	}
+
+	return &Generator{sizes: sizes, format: format}, nil
+}
+
+// Generate
+// @description: 設定された各サイズの派生画像を生成する。元画像が小さくて縮小にならないサイズは
+// 元のサイズのものを一つだけ生成し、残りのサイズはそれを使う
+func (g *Generator) Generate(src image.Image) ([]Derivative, error) {
+	bounds := src.Bounds()
+	longEdge := max(bounds.Dx(), bounds.Dy())
+
+	format := g.format
+	if format == "jpeg" && !isOpaque(src) {
+		// JPEGでは透過が失われる
+		format = "webp"
+	}
+
+	derivatives := make([]Derivative, 0, len(g.sizes))
+	var unshrunk []int
+	for _, size := range g.sizes {
+		if size >= longEdge {
+			unshrunk = append(unshrunk, size)
+			continue
+		}
+
+		d, err := encodeDerivative(src, size, format)
+		if err != nil {
+			return nil, err
+		}
+		derivatives = append(derivatives, d)
+	}
+	if len(unshrunk) == 0 {
+		return derivatives, nil
+	}
+
+	full, err := encodeDerivative(src, slices.Min(unshrunk), format)
+	if err != nil {
+		return nil, err
+	}
+	derivatives = append(derivatives, full)
+	for _, size := range unshrunk {
+		if name := strconv.Itoa(size); name != full.Name {
+			derivatives = append(derivatives, Derivative{
+				Name:   name,
+				Width:  full.Width,
+				Height: full.Height,
+				Format: full.Format,
+				SameAs: full.Name,
+			})
+		}
+	}
+	return derivatives, nil
+}
+
+// encodeDerivative
+// @description: 長辺をsizeに縮小した派生画像を書き出す
+func encodeDerivative(src image.Image, size int, format string) (Derivative, error) {
+	resized := ResizeLongEdge(src, size)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, resized, format); err != nil {
+		return Derivative{}, fmt.Errorf("failed to encode %dpx variant: %w", size, err)
+	}
+
+	bounds := resized.Bounds()
+	return Derivative{
+		Name:   strconv.Itoa(size),
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Format: format,
+		Data:   buf.Bytes(),
+	}, nil
+}
+
+// isOpaque
+// @description: 透過したピクセルがないか（判定できない型は不透明とみなす）
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
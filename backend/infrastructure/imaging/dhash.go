@@ -0,0 +1,40 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+
+	"golang.org/x/image/draw"
+)
+
+// DHash
+// @description: 差分ハッシュ（dHash）を計算する。9x8のグレースケールに縮小し、
+// 横に隣り合う画素の明暗を64ビットに詰める。縮小・再圧縮・軽い色調整では値がほとんど変わらない
+func DHash(src image.Image) uint64 {
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.ApproxBiLinear.Scale(small, small.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if grayAt(small, x, y) < grayAt(small, x+1, y) {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HammingDistance
+// @description: 2つのハッシュの異なるビットの数
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// grayAt
+// @description: グレースケール画像の輝度
+func grayAt(img *image.Gray, x, y int) uint8 {
+	return img.At(x, y).(color.Gray).Y
+}
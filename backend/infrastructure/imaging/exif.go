@@ -0,0 +1,227 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// EXIFのタグ
+const (
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+)
+
+// exifTimeLayout
+// @description: EXIFの日時の書式
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// Metadata
+// @description: EXIFから読み取った情報
+type Metadata struct {
+	Orientation int        // 1〜8（不明な場合は1）
+	Software    string     // 作成ソフトウェア
+	CreatedAt   *time.Time // 撮影・作成日時
+}
+
+// ReadMetadata
+// @description: 先頭部分からEXIFを探して読み取る（見つからなければ空の情報を返す）
+func ReadMetadata(header []byte, format string) *Metadata {
+	meta := &Metadata{Orientation: 1}
+
+	var tiff []byte
+	switch format {
+	case "jpeg":
+		tiff = findJPEGExif(header)
+	case "png":
+		tiff = findPNGExif(header)
+	case "webp":
+		tiff = findWebPExif(header)
+	}
+	if tiff != nil {
+		parseTIFF(tiff, meta)
+	}
+
+	return meta
+}
+
+// findJPEGExif
+// @description: SOSまでのAPP1セグメントからEXIFを探す
+func findJPEGExif(data []byte) []byte {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		if isStandaloneJPEGMarker(marker) {
+			pos += 2
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+
+		payload := data[pos+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return payload[6:]
+		}
+		pos = end
+	}
+	return nil
+}
+
+// findPNGExif
+// @description: IDATまでのeXIfチャンクを探す
+func findPNGExif(data []byte) []byte {
+	pos := 8
+	for pos+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		end := pos + 8 + length
+		if length < 0 || end > len(data) || chunkType == "IDAT" {
+			return nil
+		}
+		if chunkType == "eXIf" {
+			return data[pos+8 : end]
+		}
+		pos = end + 4
+	}
+	return nil
+}
+
+// findWebPExif
+// @description: 先頭部分に含まれるEXIFチャンクを探す
+func findWebPExif(data []byte) []byte {
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size
+		if size < 0 || end > len(data) {
+			return nil
+		}
+		if string(data[pos:pos+4]) == "EXIF" {
+			return bytes.TrimPrefix(data[pos+8:end], []byte("Exif\x00\x00"))
+		}
+		pos = end + size%2
+	}
+	return nil
+}
+
+// parseTIFF
+// @description: IFD0とExif IFDから必要なタグだけを読み取る
+func parseTIFF(tiff []byte, meta *Metadata) {
+	if len(tiff) < 8 {
+		return
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return
+	}
+
+	var dateTime string
+	exifOffset := 0
+	readIFD(tiff, order, int(order.Uint32(tiff[4:])), func(tag, typ uint16, count uint32, value []byte) {
+		switch tag {
+		case tagOrientation:
+			if typ == 3 && count >= 1 {
+				if o := int(order.Uint16(value)); o >= 1 && o <= 8 {
+					meta.Orientation = o
+				}
+			}
+		case tagSoftware:
+			meta.Software = readASCII(tiff, order, typ, count, value)
+		case tagDateTime:
+			dateTime = readASCII(tiff, order, typ, count, value)
+		case tagExifIFD:
+			if typ == 4 && count >= 1 {
+				exifOffset = int(order.Uint32(value))
+			}
+		}
+	})
+
+	if exifOffset > 0 {
+		readIFD(tiff, order, exifOffset, func(tag, typ uint16, count uint32, value []byte) {
+			if tag == tagDateTimeOriginal {
+				if original := readASCII(tiff, order, typ, count, value); original != "" {
+					dateTime = original
+				}
+			}
+		})
+	}
+
+	if t, err := time.Parse(exifTimeLayout, dateTime); err == nil {
+		meta.CreatedAt = &t
+	}
+}
+
+// readIFD
+// @description: IFDの各エントリに対してfnを呼ぶ（valueは4バイトの値/オフセット領域）
+func readIFD(tiff []byte, order binary.ByteOrder, offset int, fn func(tag, typ uint16, count uint32, value []byte)) {
+	if offset <= 0 || offset+2 > len(tiff) {
+		return
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		fn(order.Uint16(tiff[entry:]), order.Uint16(tiff[entry+2:]), order.Uint32(tiff[entry+4:]), tiff[entry+8:entry+12])
+	}
+}
+
+// readASCII
+// @description: ASCII型のタグの値を読み取る
+func readASCII(tiff []byte, order binary.ByteOrder, typ uint16, count uint32, value []byte) string {
+	if typ != 2 || count == 0 {
+		return ""
+	}
+
+	var data []byte
+	if count <= 4 {
+		data = value[:count]
+	} else {
+		start := int(order.Uint32(value))
+		end := start + int(count)
+		if start < 0 || end > len(tiff) || end < start {
+			return ""
+		}
+		data = tiff[start:end]
+	}
+
+	return string(bytes.TrimRight(data, "\x00 "))
+}
+
+// isStandaloneJPEGMarker
+// @description: 長さを持たないJPEGマーカーかどうか
+func isStandaloneJPEGMarker(marker byte) bool {
+	return marker == 0x01 || marker == 0xD8 || marker == 0xD9 || (marker >= 0xD0 && marker <= 0xD7)
+}
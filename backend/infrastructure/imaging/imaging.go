@@ -0,0 +1,103 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+
+	// 標準ライブラリにないフォーマットのデコーダを登録
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// jpegQuality
+// @description: JPEGで書き出すときの品質
+const jpegQuality = 85
+
+// originalJPEGQuality
+// @description: 向きを直した元画像をJPEGで書き出すときの品質
+const originalJPEGQuality = 95
+
+// Decode
+// @description: 画像をデコードしてフォーマット名と一緒に返す
+func Decode(r io.Reader) (image.Image, string, error) {
+	return image.Decode(r)
+}
+
+// ResizeLongEdge
+// @description: 長辺がsizeになるように縮小する（拡大はしない）
+func ResizeLongEdge(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	return Resize(src, width, height)
+}
+
+// Resize
+// @description: 指定サイズに拡縮する
+func Resize(src image.Image, width, height int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+// Encode
+// @description: 指定フォーマットで画像を書き出す
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case "jpeg", "jpg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		return png.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, nil)
+	case "webp":
+		// 可逆圧縮のみ。写真はJPEGより大きくなるので、透過が必要なときに使う
+		return nativewebp.Encode(w, img, nil)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// EncodeOriginal
+// @description: 元画像の代わりに保存する画像を書き出す（JPEGは劣化を抑えるため高品質にする）
+func EncodeOriginal(w io.Writer, img image.Image, format string) error {
+	if format == "jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: originalJPEGQuality})
+	}
+	return Encode(w, img, format)
+}
+
+// ContentType
+// @description: フォーマットに対応するMIMEタイプ
+func ContentType(format string) string {
+	switch format {
+	case "jpeg", "jpg":
+		return "image/jpeg"
+	case "png":
+		return "image/png"
+	case "gif":
+		return "image/gif"
+	case "webp":
+		return "image/webp"
+	default:
+		return "application/octet-stream"
+	}
+}
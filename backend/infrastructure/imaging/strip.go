@@ -0,0 +1,256 @@
+package imaging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrMalformed
+// @description: メタデータの除去中に構造が壊れていることが分かった
+var ErrMalformed = errors.New("malformed image structure")
+
+// StripMetadata
+// @description: 位置情報や端末情報を含むメタデータを取り除きながら読み出す。
+// 画素データは書き換えないため、全体を読み込まずにストリーミングで処理できる。
+// 読み終える前にやめる場合はCloseすること
+func StripMetadata(r io.Reader, format string) io.ReadCloser {
+	var strip func(w io.Writer, r *bufio.Reader) error
+	switch format {
+	case "jpeg":
+		strip = stripJPEG
+	case "png":
+		strip = stripPNG
+	case "webp":
+		strip = stripWebP
+	default:
+		// GIF・BMPはEXIFを持たない
+		return io.NopCloser(r)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(strip(pw, bufio.NewReader(r)))
+	}()
+	return pr
+}
+
+// stripJPEG
+// @description: SOSより前のAPP1（EXIF・XMP）、APP13（IPTC）、コメントなどを取り除く。
+// JFIF・ICCプロファイル・Adobeのセグメントは色の再現に必要なので残す
+func stripJPEG(w io.Writer, r *bufio.Reader) error {
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(r, soi); err != nil {
+		return err
+	}
+	if soi[0] != 0xFF || soi[1] != 0xD8 {
+		return fmt.Errorf("%w: missing JPEG SOI", ErrMalformed)
+	}
+	if _, err := w.Write(soi); err != nil {
+		return err
+	}
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != 0xFF {
+			return fmt.Errorf("%w: expected JPEG marker", ErrMalformed)
+		}
+
+		marker, err := r.ReadByte()
+		for err == nil && marker == 0xFF {
+			marker, err = r.ReadByte()
+		}
+		if err != nil {
+			return err
+		}
+
+		if isStandaloneJPEGMarker(marker) {
+			if _, err := w.Write([]byte{0xFF, marker}); err != nil {
+				return err
+			}
+			if marker == 0xD9 {
+				return nil
+			}
+			continue
+		}
+
+		var lengthBytes [2]byte
+		if _, err := io.ReadFull(r, lengthBytes[:]); err != nil {
+			return err
+		}
+		length := int64(binary.BigEndian.Uint16(lengthBytes[:]))
+		if length < 2 {
+			return fmt.Errorf("%w: invalid JPEG segment length", ErrMalformed)
+		}
+
+		if isJPEGMetadataMarker(marker) {
+			if _, err := r.Discard(int(length - 2)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := w.Write([]byte{0xFF, marker, lengthBytes[0], lengthBytes[1]}); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, length-2); err != nil {
+			return err
+		}
+
+		// SOS以降は画素データなのでそのまま流す
+		if marker == 0xDA {
+			_, err := io.Copy(w, r)
+			return err
+		}
+	}
+}
+
+// isJPEGMetadataMarker
+// @description: 取り除くJPEGセグメントかどうか
+func isJPEGMetadataMarker(marker byte) bool {
+	switch marker {
+	case 0xE0, 0xE2, 0xEE: // JFIF, ICCプロファイル, Adobe
+		return false
+	case 0xFE: // コメント
+		return true
+	}
+	return marker >= 0xE1 && marker <= 0xEF
+}
+
+// pngMetadataChunks
+// @description: 取り除くPNGチャンク
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG
+// @description: EXIF・テキスト・更新日時のチャンクを取り除く
+func stripPNG(w io.Writer, r *bufio.Reader) error {
+	signature := make([]byte, 8)
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return err
+	}
+	if _, err := w.Write(signature); err != nil {
+		return err
+	}
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return err
+		}
+		length := int64(binary.BigEndian.Uint32(header[:4]))
+		chunkType := string(header[4:])
+
+		// データ + CRC
+		if pngMetadataChunks[chunkType] {
+			if _, err := io.CopyN(io.Discard, r, length+4); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(w, r, length+4); err != nil {
+			return err
+		}
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
+
+// VP8Xチャンクのフラグ
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+// stripWebP
+// @description: EXIF・XMPチャンクの中身を消して未知のチャンクに置き換える。
+// チャンクを削るとRIFF全体のサイズが変わり、先頭を書き直すまで出力できないため、
+// サイズを変えずにデコーダが無視するチャンクにする
+func stripWebP(w io.Writer, r *bufio.Reader) error {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WEBP" {
+		return fmt.Errorf("%w: missing RIFF header", ErrMalformed)
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	var chunkHeader [8]byte
+	for {
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		size := int64(binary.LittleEndian.Uint32(chunkHeader[4:]))
+		padded := size + size%2
+
+		switch string(chunkHeader[:4]) {
+		case "VP8X":
+			if size != 10 {
+				return fmt.Errorf("%w: invalid VP8X chunk size", ErrMalformed)
+			}
+			data := make([]byte, padded)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return err
+			}
+			if len(data) > 0 {
+				data[0] &^= webpFlagEXIF | webpFlagXMP
+			}
+			if _, err := w.Write(chunkHeader[:]); err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		case "EXIF", "XMP ":
+			if _, err := r.Discard(int(padded)); err != nil {
+				return err
+			}
+			copy(chunkHeader[:4], "JUNK")
+			if _, err := w.Write(chunkHeader[:]); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(w, zeroReader{}, padded); err != nil {
+				return err
+			}
+		default:
+			if _, err := w.Write(chunkHeader[:]); err != nil {
+				return err
+			}
+			if _, err := io.CopyN(w, r, padded); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// zeroReader
+// @description: 0を返し続けるReader
+type zeroReader struct{}
+
+// Read
+// @description: io.Readerを実装
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
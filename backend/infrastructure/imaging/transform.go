@@ -0,0 +1,81 @@
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// Transform
+// @description: fitに従って画像をwidth×heightに変換する（0の辺はアスペクト比から決める）。
+// どのfitでも元画像より大きくはしない（枠が元画像より大きい場合は出力が枠より小さくなる）
+//   - contain: 枠内に収まるように縮小
+//   - cover: 枠を覆うように縮小して中央を切り抜き（枠が元画像に収まらない場合は枠のアスペクト比のまま縮める）
+//   - fill: アスペクト比を無視して変形（各辺は元画像の辺までに抑える）
+func Transform(src image.Image, width, height int, fit string) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	if width == 0 || height == 0 || fit == "contain" {
+		w, h := containSize(srcW, srcH, width, height)
+		if w == srcW && h == srcH {
+			return src
+		}
+		return Resize(src, w, h)
+	}
+
+	if fit == "fill" {
+		width, height = min(width, srcW), min(height, srcH)
+		if width == srcW && height == srcH {
+			return src
+		}
+		return Resize(src, width, height)
+	}
+
+	width, height = coverSize(srcW, srcH, width, height)
+
+	// cover: 目標のアスペクト比で切り抜いてから縮小
+	crop := bounds
+	if srcW*height > srcH*width {
+		cropW := srcH * width / height
+		crop.Min.X += (srcW - cropW) / 2
+		crop.Max.X = crop.Min.X + cropW
+	} else {
+		cropH := srcW * height / width
+		crop.Min.Y += (srcH - cropH) / 2
+		crop.Max.Y = crop.Min.Y + cropH
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+	return dst
+}
+
+// containSize
+// @description: 元画像を拡大せずに枠内に収めたときのサイズ
+func containSize(srcW, srcH, width, height int) (int, int) {
+	if width == 0 || width > srcW {
+		width = srcW
+	}
+	if height == 0 || height > srcH {
+		height = srcH
+	}
+
+	if srcW*height > srcH*width {
+		return width, max(1, srcH*width/srcW)
+	}
+	return max(1, srcW*height/srcH), height
+}
+
+// coverSize
+// @description: 枠のアスペクト比を保ったまま、元画像を拡大せずに切り抜ける大きさまで枠を縮める
+func coverSize(srcW, srcH, width, height int) (int, int) {
+	if width <= srcW && height <= srcH {
+		return width, height
+	}
+
+	if width*srcH > height*srcW {
+		return srcW, max(1, height*srcW/width)
+	}
+	return max(1, width*srcH/height), srcH
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxPixels
+// @description: デコードを許可する総ピクセル数の上限（IMAGE_MAX_PIXELSで上書き可能）
+const defaultMaxPixels = 50_000_000
+
+// maxDimension
+// @description: 幅・高さそれぞれの上限
+const maxDimension = 20000
+
+// @description: 画像検証のエラー
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrFormatMismatch    = errors.New("file extension does not match image content")
+	ErrTooManyPixels     = errors.New("image dimensions too large")
+	ErrTruncated         = errors.New("image header is cut short") // 渡された先頭部分に形式とサイズが収まっていない
+)
+
+// extensionFormats
+// @description: 拡張子ごとに許可するフォーマット
+var extensionFormats = map[string]string{
+	".jpg":  "jpeg",
+	".jpeg": "jpeg",
+	".png":  "png",
+	".gif":  "gif",
+	".webp": "webp",
+	".bmp":  "bmp",
+}
+
+// SupportedExtension
+// @description: アップロードを受け付ける拡張子かどうか
+func SupportedExtension(filename string) bool {
+	_, ok := extensionFormats[strings.ToLower(filepath.Ext(filename))]
+	return ok
+}
+
+// Info
+// @description: ヘッダから読み取った画像の情報
+type Info struct {
+	Format string
+	Width  int
+	Height int
+}
+
+// Sniff
+// @description: マジックバイトから画像フォーマットを判定
+func Sniff(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(header, []byte("\xff\xd8\xff")):
+		return "jpeg"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		return "gif"
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return "webp"
+	case bytes.HasPrefix(header, []byte("BM")):
+		return "bmp"
+	default:
+		return ""
+	}
+}
+
+// Inspect
+// @description: 中身から形式とサイズを読み取り、拡張子との一致とピクセル数を検証する
+func Inspect(r io.Reader, filename string) (*Info, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(12)
+
+	format := Sniff(header)
+	if format == "" {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if expected, ok := extensionFormats[strings.ToLower(filepath.Ext(filename))]; !ok || expected != format {
+		return nil, ErrFormatMismatch
+	}
+
+	config, decodedFormat, err := image.DecodeConfig(br)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return nil, ErrTruncated
+	}
+	if err != nil || decodedFormat != format {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width > maxDimension || config.Height > maxDimension || int64(config.Width)*int64(config.Height) > maxPixels() {
+		return nil, ErrTooManyPixels
+	}
+
+	return &Info{Format: format, Width: config.Width, Height: config.Height}, nil
+}
+
+// maxPixels
+// @description: 総ピクセル数の上限を取得
+func maxPixels() int64 {
+	if env := os.Getenv("IMAGE_MAX_PIXELS"); env != "" {
+		if n, err := strconv.ParseInt(env, 10, 64); err == nil && n > 0 {
+			return n
+		}
+		fmt.Printf("Invalid IMAGE_MAX_PIXELS %q, using default\n", env)
+	}
+	return defaultMaxPixels
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"backend/domain"
 	"backend/usecase"
 	"net/http"
 	"strings"
@@ -90,15 +91,57 @@ func OptionalAuthMiddleware() echo.MiddlewareFunc {
 	}
 }
 
+// QueryTokenMiddleware lets clients that cannot set headers, such as the browser EventSource,
+// pass the JWT as ?access_token=. It must run before AuthMiddleware.
+func QueryTokenMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				if token := c.QueryParam("access_token"); token != "" {
+					c.Request().Header.Set("Authorization", "Bearer "+token)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// AdminMiddleware allows only administrators. It must run after AuthMiddleware.
+// The flag is read from the database so that revoking it takes effect immediately.
+func AdminMiddleware(userRepo domain.UserRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("user_id").(uint)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Unauthorized",
+				})
+			}
+
+			user, err := userRepo.GetByID(userID)
+			if err != nil || !user.IsAdmin {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Administrator access is required",
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // CORSMiddleware handles CORS
 func CORSMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			c.Response().Header().Set("Access-Control-Allow-Origin", "*")
-			c.Response().Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			c.Response().Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, HEAD, DELETE, OPTIONS")
+			c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata")
+			c.Response().Header().Set("Access-Control-Expose-Headers", "Location, Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Upload-Offset, Upload-Length, Upload-Expires, Upload-Image-Id")
 
-			if c.Request().Method == "OPTIONS" {
+			// プリフライトのみここで返し、tusのOPTIONSはハンドラに渡す
+			if c.Request().Method == "OPTIONS" && c.Request().Header.Get("Access-Control-Request-Method") != "" {
 				return c.NoContent(http.StatusOK)
 			}
 
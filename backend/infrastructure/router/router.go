@@ -3,8 +3,11 @@ package router
 import (
 	"backend/controller"
 	"backend/infrastructure/middleware"
+	"backend/infrastructure/stream"
+	"backend/infrastructure/worker"
 	"backend/repository"
 	"backend/usecase"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -22,16 +25,58 @@ func SetupRoutes(db *gorm.DB) *echo.Echo {
 	userRepo := repository.NewUserRepository(db)
 	imageRepo := repository.NewImageRepository(db)
 	postRepo := repository.NewPostRepository(db)
+	uploadRepo := repository.NewUploadRepository(db)
+	blobRepo := repository.NewBlobRepository(db)
+	duplicateRepo := repository.NewDuplicateFlagRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	shareRepo := repository.NewShareLinkRepository(db)
+	albumRepo := repository.NewAlbumRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+	feedRepo := repository.NewFeedRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	// In-process event hub for Server-Sent Events
+	streamHub := stream.NewHub()
 
 	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo)
-	imageUseCase := usecase.NewImageUseCase(imageRepo)
-	postUseCase := usecase.NewPostUseCase(postRepo, imageRepo)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo)
+	userUseCase := usecase.NewUserUseCase(userRepo, webhookUseCase)
+	notificationUseCase := usecase.NewNotificationUseCase(notificationRepo, streamHub)
+	imageUseCase := usecase.NewImageUseCase(imageRepo, blobRepo, duplicateRepo, outboxRepo, bookmarkRepo)
+	postUseCase := usecase.NewPostUseCase(postRepo, imageRepo, bookmarkRepo, notificationUseCase, streamHub, webhookUseCase)
+	uploadUseCase := usecase.NewUploadUseCase(uploadRepo, imageUseCase)
+	storageUseCase := usecase.NewStorageUseCase(outboxRepo, blobRepo)
+	trashUseCase := usecase.NewTrashUseCase(imageRepo, postRepo)
+	shareUseCase := usecase.NewShareUseCase(shareRepo, imageRepo, postRepo)
+	albumUseCase := usecase.NewAlbumUseCase(albumRepo, imageRepo, postRepo, userRepo)
+	bookmarkUseCase := usecase.NewBookmarkUseCase(bookmarkRepo, imageRepo, postRepo)
+	followUseCase := usecase.NewFollowUseCase(followRepo, feedRepo, userRepo, notificationUseCase)
 
 	// Initialize controllers
 	authController := controller.NewAuthController(userUseCase)
 	imageController := controller.NewImageController(imageUseCase)
 	postController := controller.NewPostController(postUseCase)
+	uploadController := controller.NewUploadController(uploadUseCase, usecase.MaxUploadBytes())
+	adminController := controller.NewAdminController(imageUseCase)
+	trashController := controller.NewTrashController(trashUseCase)
+	shareController := controller.NewShareController(shareUseCase)
+	albumController := controller.NewAlbumController(albumUseCase)
+	bookmarkController := controller.NewBookmarkController(bookmarkUseCase)
+	followController := controller.NewFollowController(followUseCase)
+	notificationController := controller.NewNotificationController(notificationUseCase)
+	streamController := controller.NewStreamController(streamHub)
+	webhookController := controller.NewWebhookController(webhookUseCase)
+
+	// Background jobs
+	worker.Every(time.Hour, "purge expired uploads", uploadUseCase.PurgeExpiredUploads)
+	worker.Every(time.Minute, "process storage outbox", storageUseCase.ProcessOutbox)
+	worker.Every(24*time.Hour, "reconcile storage", storageUseCase.ReconcileStorage)
+	worker.Every(time.Hour, "purge trash", trashUseCase.PurgeExpired)
+	worker.Every(time.Minute, "publish scheduled posts", postUseCase.PublishScheduledPosts)
+	worker.Every(10*time.Second, "deliver webhooks", webhookUseCase.DeliverWebhooks)
+	worker.Every(24*time.Hour, "purge webhook deliveries", webhookUseCase.PurgeDeliveries)
 
 	// Public routes
 	e.GET("/", func(c echo.Context) error {
@@ -59,16 +104,82 @@ func SetupRoutes(db *gorm.DB) *echo.Echo {
 	api.POST("/images", imageController.UploadImage)
 	api.GET("/images/my", imageController.GetUserImages)
 	api.GET("/images/:id", imageController.GetImage)
+	api.GET("/images/:id/similar", imageController.GetSimilarImages)
 	api.PUT("/images/:id", imageController.UpdateImage)
+	api.PUT("/images/:id/file", imageController.ReplaceImageFile)
+	api.GET("/images/:id/revisions", imageController.GetImageRevisions)
+	api.POST("/images/:id/revisions/:revision/revert", imageController.RevertImage)
 	api.DELETE("/images/:id", imageController.DeleteImage)
 
+	// Resumable upload routes (tus protocol)
+	e.OPTIONS("/api/uploads", uploadController.Options)
+	api.POST("/uploads", uploadController.CreateUpload)
+	api.HEAD("/uploads/:id", uploadController.GetUploadOffset)
+	api.PATCH("/uploads/:id", uploadController.PatchUpload)
+	api.DELETE("/uploads/:id", uploadController.DeleteUpload)
+
 	// Post routes
 	api.POST("/posts", postController.CreatePost)
 	api.GET("/posts/my", postController.GetUserPosts)
 	api.GET("/posts/:id", postController.GetPost)
 	api.PUT("/posts/:id", postController.UpdatePost)
+	api.PUT("/posts/:id/stages", postController.SetPostStages)
 	api.DELETE("/posts/:id", postController.DeletePost)
 
+	// Trash routes
+	api.GET("/trash", trashController.GetTrash)
+	api.POST("/trash/:type/:id/restore", trashController.Restore)
+
+	// Album routes
+	api.POST("/albums", albumController.CreateAlbum)
+	api.GET("/albums/my", albumController.GetMyAlbums)
+	api.GET("/albums/:id", albumController.GetAlbum)
+	api.PUT("/albums/:id", albumController.UpdateAlbum)
+	api.DELETE("/albums/:id", albumController.DeleteAlbum)
+	api.POST("/albums/:id/items", albumController.AddAlbumItem)
+	api.PUT("/albums/:id/items/order", albumController.ReorderAlbumItems)
+	api.DELETE("/albums/:id/items/:itemId", albumController.RemoveAlbumItem)
+
+	// Share link routes
+	api.POST("/images/:id/shares", shareController.CreateImageShare)
+	api.GET("/images/:id/shares", shareController.GetImageShares)
+	api.POST("/posts/:id/shares", shareController.CreatePostShare)
+	api.GET("/posts/:id/shares", shareController.GetPostShares)
+	api.DELETE("/shares/:id", shareController.RevokeShare)
+
+	// Bookmark routes (private to the user)
+	api.POST("/bookmarks", bookmarkController.AddBookmark)
+	api.GET("/bookmarks", bookmarkController.GetBookmarks)
+	api.DELETE("/bookmarks", bookmarkController.RemoveBookmark)
+	api.GET("/bookmarks/folders", bookmarkController.GetFolders)
+	api.POST("/bookmarks/folders", bookmarkController.CreateFolder)
+	api.PUT("/bookmarks/folders/:id", bookmarkController.RenameFolder)
+	api.DELETE("/bookmarks/folders/:id", bookmarkController.DeleteFolder)
+
+	// Follow and feed routes
+	api.POST("/users/:username/follow", followController.Follow)
+	api.DELETE("/users/:username/follow", followController.Unfollow)
+	api.GET("/feed", followController.GetFeed)
+
+	// Notification routes
+	api.GET("/notifications", notificationController.GetNotifications)
+	api.POST("/notifications/read-all", notificationController.MarkAllRead)
+	api.POST("/notifications/:id/read", notificationController.MarkRead)
+	api.GET("/notifications/preferences", notificationController.GetPreferences)
+	api.PUT("/notifications/preferences", notificationController.UpdatePreferences)
+
+	// Admin routes
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware(userRepo))
+	admin.GET("/duplicates", adminController.GetDuplicateFlags)
+	admin.PUT("/duplicates/:id", adminController.ReviewDuplicateFlag)
+	admin.GET("/webhooks", webhookController.GetWebhooks)
+	admin.POST("/webhooks", webhookController.CreateWebhook)
+	admin.PUT("/webhooks/:id", webhookController.UpdateWebhook)
+	admin.DELETE("/webhooks/:id", webhookController.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", webhookController.GetDeliveries)
+	admin.POST("/webhooks/:id/ping", webhookController.PingWebhook)
+
 	// Public routes (no auth required)
 	public := e.Group("/public")
 	public.Use(middleware.OptionalAuthMiddleware())
@@ -76,11 +187,31 @@ func SetupRoutes(db *gorm.DB) *echo.Echo {
 	// Public image routes
 	public.GET("/images", imageController.GetPublicImages)
 	public.GET("/images/search", imageController.SearchImages)
+	public.GET("/images/:id", imageController.GetImage)
+	public.GET("/images/:id/transform-url", imageController.GetTransformURL)
 
 	// Public post routes
 	public.GET("/posts", postController.GetPublicPosts)
 	public.GET("/posts/search", postController.SearchPosts)
 	public.GET("/posts/tags", postController.GetPostsByTags)
+	public.GET("/posts/:id", postController.GetPost)
+
+	// Public album routes
+	public.GET("/users/:username/albums", albumController.GetUserAlbums)
+	public.GET("/users/:username/albums/:id", albumController.GetUserAlbum)
+
+	// Public follow lists
+	public.GET("/users/:username/followers", followController.GetFollowers)
+	public.GET("/users/:username/following", followController.GetFollowing)
+
+	// Image transformation routes (signed URLs, no auth required)
+	e.GET("/img/:id", imageController.TransformImage)
+
+	// Server-Sent Events; EventSource cannot set headers, so the token may also come as ?access_token=
+	e.GET("/api/stream", streamController.Stream, middleware.QueryTokenMiddleware(), middleware.AuthMiddleware())
+
+	// Share links (signed tokens, no auth required)
+	e.GET("/s/:token", shareController.ResolveShare)
 
 	return e
 }
@@ -0,0 +1,192 @@
+package stream
+
+import (
+	"backend/domain"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// replaySize
+	// @description: 再接続時に再送できるよう覚えておく直近のイベント数
+	replaySize = 256
+
+	// subscriberBuffer
+	// @description: 購読者ごとに溜めておけるイベント数。溢れた購読者は切断し、再接続時の再送に任せる
+	subscriberBuffer = 64
+)
+
+// Hub
+// @description: プロセス内のpub/sub。ユーザー宛てとトピック宛てのイベントを購読者に配り、
+// 直近のイベントをリングバッファに残してLast-Event-IDからの再送に使う
+type Hub struct {
+	mu      sync.Mutex
+	seq     uint64
+	ring    [replaySize]entry
+	next    int // ringの次に書き込む位置
+	stored  int // ringに入っているイベント数
+	subs    map[*domain.StreamSubscription]*subscriber
+	byUser  map[uint]map[*domain.StreamSubscription]*subscriber
+	byTopic map[string]map[*domain.StreamSubscription]*subscriber
+}
+
+// entry
+// @description: リングバッファに残すイベントと宛先
+type entry struct {
+	event  domain.StreamEvent
+	userID uint   // ユーザー宛ての場合
+	topic  string // トピック宛ての場合
+}
+
+// subscriber
+// @description: 接続中のクライアント
+type subscriber struct {
+	userID uint
+	topics []string
+	ch     chan domain.StreamEvent
+}
+
+// NewHub
+// @description: ハブを初期化。
+// 連番は起動時刻から始め、再起動後も前の接続のLast-Event-IDより大きくなるようにする
+func NewHub() *Hub {
+	return &Hub{
+		seq:     uint64(time.Now().UnixMicro()),
+		subs:    make(map[*domain.StreamSubscription]*subscriber),
+		byUser:  make(map[uint]map[*domain.StreamSubscription]*subscriber),
+		byTopic: make(map[string]map[*domain.StreamSubscription]*subscriber),
+	}
+}
+
+// PublishToUser
+// @description: ユーザー宛てにイベントを送る
+func (h *Hub) PublishToUser(userID uint, eventType string, data interface{}) {
+	h.publish(entry{userID: userID}, eventType, data)
+}
+
+// PublishToTopic
+// @description: トピックの購読者にイベントを送る
+func (h *Hub) PublishToTopic(topic, eventType string, data interface{}) {
+	h.publish(entry{topic: topic}, eventType, data)
+}
+
+// publish
+// @description: イベントに連番を振ってリングバッファに残し、宛先の購読者に配る
+func (h *Hub) publish(e entry, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s stream event: %v", eventType, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	e.event = domain.StreamEvent{ID: h.seq, Type: eventType, Data: payload}
+	h.ring[h.next] = e
+	h.next = (h.next + 1) % replaySize
+	if h.stored < replaySize {
+		h.stored++
+	}
+
+	targets := h.byTopic[e.topic]
+	if e.topic == "" {
+		targets = h.byUser[e.userID]
+	}
+	for sub, s := range targets {
+		select {
+		case s.ch <- e.event:
+		default:
+			h.remove(sub, s)
+		}
+	}
+}
+
+// Subscribe
+// @description: ユーザー宛てと指定したトピックのイベントの購読を開始する。
+// 再送分の取り出しと登録を同じロックの中で行うので、その間のイベントを取りこぼさない
+func (h *Hub) Subscribe(userID uint, topics []string, lastEventID uint64) *domain.StreamSubscription {
+	ch := make(chan domain.StreamEvent, subscriberBuffer)
+	sub := &domain.StreamSubscription{Events: ch}
+	s := &subscriber{userID: userID, topics: topics, ch: ch}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if lastEventID > 0 {
+		sub.Replay = h.replay(s, lastEventID)
+	}
+
+	h.subs[sub] = s
+	if h.byUser[userID] == nil {
+		h.byUser[userID] = make(map[*domain.StreamSubscription]*subscriber)
+	}
+	h.byUser[userID][sub] = s
+	for _, topic := range topics {
+		if h.byTopic[topic] == nil {
+			h.byTopic[topic] = make(map[*domain.StreamSubscription]*subscriber)
+		}
+		h.byTopic[topic][sub] = s
+	}
+
+	return sub
+}
+
+// Unsubscribe
+// @description: 購読をやめる（すでに切断されている場合は何もしない）
+func (h *Hub) Unsubscribe(sub *domain.StreamSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if s, ok := h.subs[sub]; ok {
+		h.remove(sub, s)
+	}
+}
+
+// replay
+// @description: lastEventIDより後の、購読者宛てのイベントを古い順に返す
+func (h *Hub) replay(s *subscriber, lastEventID uint64) []domain.StreamEvent {
+	var events []domain.StreamEvent
+	start := (h.next - h.stored + replaySize) % replaySize
+	for i := 0; i < h.stored; i++ {
+		e := h.ring[(start+i)%replaySize]
+		if e.event.ID > lastEventID && s.wants(e) {
+			events = append(events, e.event)
+		}
+	}
+	return events
+}
+
+// remove
+// @description: 購読者を登録から外してチャネルを閉じる（h.muを持った状態で呼ぶ）
+func (h *Hub) remove(sub *domain.StreamSubscription, s *subscriber) {
+	delete(h.subs, sub)
+	delete(h.byUser[s.userID], sub)
+	if len(h.byUser[s.userID]) == 0 {
+		delete(h.byUser, s.userID)
+	}
+	for _, topic := range s.topics {
+		delete(h.byTopic[topic], sub)
+		if len(h.byTopic[topic]) == 0 {
+			delete(h.byTopic, topic)
+		}
+	}
+	close(s.ch)
+}
+
+// wants
+// @description: イベントが購読者宛てかどうか
+func (s *subscriber) wants(e entry) bool {
+	if e.topic == "" {
+		return e.userID == s.userID
+	}
+	for _, topic := range s.topics {
+		if topic == e.topic {
+			return true
+		}
+	}
+	return false
+}
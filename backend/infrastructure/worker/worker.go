@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"log"
+	"time"
+)
+
+// Every
+// @description: fnを一定間隔で実行するバックグラウンドジョブを開始
+func Every(interval time.Duration, name string, fn func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := fn(); err != nil {
+				log.Printf("Background job %q failed: %v", name, err)
+			}
+		}
+	}()
+}
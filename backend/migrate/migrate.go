@@ -4,13 +4,83 @@ package main
 
 import (
 	"backend/infrastructure/db"
-	"backend/model"
 	"fmt"
+	"log"
+	"os"
+	"strconv"
 )
 
+const usage = `Usage: go run ./migrate <command>
+
+Commands:
+  up           未適用のマイグレーションをすべて適用
+  down [n]     直近n件（デフォルト1件）をロールバック
+  status       マイグレーションの適用状況を表示
+  to <version> 指定バージョンまで適用またはロールバック`
+
 func main() {
-	dbConn := db.ConnectDB()
-	defer fmt.Println("Successfully Migrated")
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	dbConn := db.OpenDB()
 	defer db.CloseDB(dbConn)
-	dbConn.AutoMigrate(&model.Users{}, &model.Posts{})
+
+	migrator, err := db.NewMigrator(dbConn)
+	if err != nil {
+		log.Fatalln("Failed to load migrations:", err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatalln("Invalid number of steps:", os.Args[2])
+			}
+		}
+		err = migrator.Down(steps)
+	case "status":
+		err = printStatus(migrator)
+	case "to":
+		if len(os.Args) < 3 {
+			log.Fatalln("Target version is required")
+		}
+		version, parseErr := strconv.ParseInt(os.Args[2], 10, 64)
+		if parseErr != nil || version < 0 {
+			log.Fatalln("Invalid version:", os.Args[2])
+		}
+		err = migrator.To(version)
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalln("Migration failed:", err)
+	}
+	if os.Args[1] != "status" {
+		fmt.Println("Successfully Migrated")
+	}
+}
+
+// printStatus prints each migration with its applied time
+func printStatus(migrator *db.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+	}
+	return nil
 }
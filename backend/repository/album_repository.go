@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// albumRepository implements domain.AlbumRepository
+type albumRepository struct {
+	db *gorm.DB
+}
+
+// NewAlbumRepository creates a new album repository
+func NewAlbumRepository(db *gorm.DB) domain.AlbumRepository {
+	return &albumRepository{db: db}
+}
+
+// Create creates a new album
+func (r *albumRepository) Create(album *domain.Album) error {
+	return r.db.Omit(clause.Associations).Create(album).Error
+}
+
+// GetByID retrieves an album by ID, with its items in order
+func (r *albumRepository) GetByID(id uint) (*domain.Album, error) {
+	var album domain.Album
+	err := r.db.Preload("User").Preload("CoverImage").
+		Preload("Items", orderedAlbumItems).
+		Preload("Items.Image.User").
+		Preload("Items.Post.User").Preload("Items.Post.Images").
+		First(&album, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	album.ItemCount = len(album.Items)
+	return &album, nil
+}
+
+// GetByUserID retrieves a user's albums with their item counts, newest first.
+// If visibilities is nil, albums of every visibility are returned.
+func (r *albumRepository) GetByUserID(userID uint, visibilities []string, offset, limit int) ([]*domain.Album, error) {
+	query := r.db.Model(&domain.Album{}).
+		Select("albums.*, (SELECT COUNT(*) FROM album_items WHERE album_items.album_id = albums.id) AS item_count").
+		Where("albums.user_id = ?", userID)
+	if visibilities != nil {
+		query = query.Where("albums.visibility IN ?", visibilities)
+	}
+
+	var albums []*domain.Album
+	err := query.Preload("CoverImage").
+		Offset(offset).Limit(limit).
+		Order("albums.created_at DESC").
+		Find(&albums).Error
+	return albums, err
+}
+
+// Update updates an album's own columns
+func (r *albumRepository) Update(album *domain.Album) error {
+	return r.db.Omit(clause.Associations).Save(album).Error
+}
+
+// Delete deletes an album; its items are removed by the foreign key cascade
+func (r *albumRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.Album{}, id).Error
+}
+
+// AddItem appends an item to an album. The album row is locked so concurrent additions get distinct positions.
+func (r *albumRepository) AddItem(item *domain.AlbumItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&domain.Album{}, item.AlbumID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+
+		var exists int64
+		query := tx.Model(&domain.AlbumItem{}).Where("album_id = ?", item.AlbumID)
+		if item.ImageID != nil {
+			query = query.Where("image_id = ?", *item.ImageID)
+		} else {
+			query = query.Where("post_id = ?", item.PostID)
+		}
+		if err := query.Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return domain.ErrConflict
+		}
+
+		var next int
+		err = tx.Model(&domain.AlbumItem{}).
+			Where("album_id = ?", item.AlbumID).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Scan(&next).Error
+		if err != nil {
+			return err
+		}
+
+		item.Position = next
+		return tx.Omit(clause.Associations).Create(item).Error
+	})
+}
+
+// RemoveItem removes an item from an album and closes the gap in positions
+func (r *albumRepository) RemoveItem(albumID, itemID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item domain.AlbumItem
+		err := tx.Clauses(clause.Returning{}).
+			Where("id = ? AND album_id = ?", itemID, albumID).
+			Delete(&item).Error
+		if err != nil {
+			return err
+		}
+		if item.ID == 0 {
+			return domain.ErrNotFound
+		}
+
+		return tx.Model(&domain.AlbumItem{}).
+			Where("album_id = ? AND position > ?", albumID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+// ReorderItems sets the positions of an album's items to the order of itemIDs,
+// which must list every item of the album exactly once
+func (r *albumRepository) ReorderItems(albumID uint, itemIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&domain.Album{}, albumID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+
+		var current []uint
+		if err := tx.Model(&domain.AlbumItem{}).Where("album_id = ?", albumID).Pluck("id", &current).Error; err != nil {
+			return err
+		}
+		if len(current) != len(itemIDs) {
+			return domain.ErrInvalidInput
+		}
+		remaining := make(map[uint]bool, len(current))
+		for _, id := range current {
+			remaining[id] = true
+		}
+		for _, id := range itemIDs {
+			if !remaining[id] {
+				return domain.ErrInvalidInput
+			}
+			delete(remaining, id)
+		}
+
+		for position, id := range itemIDs {
+			err := tx.Model(&domain.AlbumItem{}).Where("id = ?", id).Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// orderedAlbumItems orders preloaded album items by position
+func orderedAlbumItems(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// blobRepository implements domain.BlobRepository
+type blobRepository struct {
+	db *gorm.DB
+}
+
+// NewBlobRepository creates a new blob repository
+func NewBlobRepository(db *gorm.DB) domain.BlobRepository {
+	return &blobRepository{db: db}
+}
+
+// Acquire increments the reference count of an existing blob
+func (r *blobRepository) Acquire(sha256 string) (*domain.Blob, error) {
+	var blob domain.Blob
+	result := r.db.Raw("UPDATE blobs SET ref_count = ref_count + 1, updated_at = NOW() WHERE sha256 = ? RETURNING *", sha256).
+		Scan(&blob)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &blob, nil
+}
+
+// CreateOrAcquire inserts a blob with one reference, or adds a reference if another
+// upload of the same content created it first
+func (r *blobRepository) CreateOrAcquire(blob *domain.Blob) (*domain.Blob, error) {
+	blob.RefCount = 1
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "sha256"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"ref_count":  gorm.Expr("blobs.ref_count + 1"),
+				"updated_at": gorm.Expr("NOW()"),
+			}),
+		},
+		clause.Returning{},
+	).Create(blob).Error
+	if err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// GetByCloudinaryID retrieves a blob by the public ID of its original
+func (r *blobRepository) GetByCloudinaryID(publicID string) (*domain.Blob, error) {
+	var blob domain.Blob
+	err := r.db.Where("cloudinary_id = ?", publicID).First(&blob).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// SetVariants stores the variants on the blob and copies them to the images and revisions using it
+func (r *blobRepository) SetVariants(publicID string, variants domain.ImageVariants) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Blob{}).Where("cloudinary_id = ?", publicID).
+			Updates(map[string]interface{}{"variants": variants, "updated_at": gorm.Expr("NOW()")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		if err := tx.Exec("UPDATE images SET variants = ? WHERE cloudinary_id = ?", variants, publicID).Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE image_revisions SET variants = ? WHERE cloudinary_id = ?", variants, publicID).Error
+	})
+}
+
+// Release decrements the reference count. When it reaches zero the blob row is deleted and
+// deletion of its files is queued in the same transaction. The deleted blob is returned.
+func (r *blobRepository) Release(sha256 string) (*domain.Blob, error) {
+	var removed *domain.Blob
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		removed, err = releaseBlob(tx, sha256)
+		return err
+	})
+	return removed, err
+}
+
+// releaseBlob decrements the reference count using tx and returns the blob if it was deleted
+func releaseBlob(tx *gorm.DB, sha256 string) (*domain.Blob, error) {
+	var blob domain.Blob
+	result := tx.Raw("UPDATE blobs SET ref_count = ref_count - 1, updated_at = NOW() WHERE sha256 = ? RETURNING *", sha256).
+		Scan(&blob)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrNotFound
+	}
+	if blob.RefCount > 0 {
+		return nil, nil
+	}
+
+	if err := tx.Where("sha256 = ?", sha256).Delete(&domain.Blob{}).Error; err != nil {
+		return nil, err
+	}
+	if err := enqueueImageDeletes(tx, blob.CloudinaryID, blob.Variants); err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
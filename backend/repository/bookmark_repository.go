@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bookmarkRepository implements domain.BookmarkRepository
+type bookmarkRepository struct {
+	db *gorm.DB
+}
+
+// NewBookmarkRepository creates a new bookmark repository
+func NewBookmarkRepository(db *gorm.DB) domain.BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// bookmarkTarget returns the column referencing the bookmarked item
+func bookmarkTarget(itemType string) string {
+	if itemType == domain.BookmarkItemPost {
+		return "post_id"
+	}
+	return "image_id"
+}
+
+// Save creates a bookmark, or moves the existing bookmark of the same item to the bookmark's folder
+func (r *bookmarkRepository) Save(bookmark *domain.Bookmark) error {
+	target := bookmarkTarget(bookmark.ItemType)
+	return r.db.Omit(clause.Associations).Clauses(
+		clause.OnConflict{
+			Columns:     []clause.Column{{Name: "user_id"}, {Name: target}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: target + " IS NOT NULL"}}},
+			DoUpdates:   clause.AssignmentColumns([]string{"folder_id", "updated_at"}),
+		},
+		clause.Returning{},
+	).Create(bookmark).Error
+}
+
+// Delete deletes the user's bookmark of an item
+func (r *bookmarkRepository) Delete(userID uint, itemType string, itemID uint) error {
+	result := r.db.Where("user_id = ? AND "+bookmarkTarget(itemType)+" = ?", userID, itemID).
+		Delete(&domain.Bookmark{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetByUserID retrieves the user's bookmarks, newest first. Bookmarks of items that were deleted or
+// are no longer viewable by the user are filtered out in the query so pages stay full.
+func (r *bookmarkRepository) GetByUserID(userID uint, folderID *uint, offset, limit int) ([]*domain.Bookmark, error) {
+	viewable := domain.ViewableVisibilities(userID)
+	query := r.db.Model(&domain.Bookmark{}).
+		Joins("LEFT JOIN images ON images.id = bookmarks.image_id AND images.deleted_at IS NULL").
+		Joins("LEFT JOIN posts ON posts.id = bookmarks.post_id AND posts.deleted_at IS NULL").
+		Where("bookmarks.user_id = ?", userID).
+		Where("((images.id IS NOT NULL AND (images.user_id = ? OR images.visibility IN ?))"+
+			" OR (posts.id IS NOT NULL AND (posts.user_id = ? OR (posts.visibility IN ? AND posts.status = ?))))",
+			userID, viewable, userID, viewable, domain.PostStatusPublished)
+	if folderID != nil {
+		query = query.Where("bookmarks.folder_id = ?", *folderID)
+	}
+
+	var bookmarks []*domain.Bookmark
+	err := query.
+		Preload("Image.User").
+		Preload("Post.User").Preload("Post.Images").
+		Offset(offset).Limit(limit).
+		Order("bookmarks.created_at DESC").
+		Find(&bookmarks).Error
+	return bookmarks, err
+}
+
+// CountByItems counts bookmarks per item
+func (r *bookmarkRepository) CountByItems(itemType string, itemIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(itemIDs))
+	if len(itemIDs) == 0 {
+		return counts, nil
+	}
+
+	target := bookmarkTarget(itemType)
+	var rows []struct {
+		ItemID uint
+		Count  int64
+	}
+	err := r.db.Model(&domain.Bookmark{}).
+		Select(target+" AS item_id, COUNT(*) AS count").
+		Where(target+" IN ?", itemIDs).
+		Group(target).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ItemID] = row.Count
+	}
+	return counts, nil
+}
+
+// CreateFolder creates a bookmark folder, returning domain.ErrConflict if the user already has one with the same name
+func (r *bookmarkRepository) CreateFolder(folder *domain.BookmarkFolder) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(folder)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// GetFolder retrieves a bookmark folder by ID
+func (r *bookmarkRepository) GetFolder(id uint) (*domain.BookmarkFolder, error) {
+	var folder domain.BookmarkFolder
+	err := r.db.First(&folder, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// GetFolders retrieves the user's bookmark folders by name
+func (r *bookmarkRepository) GetFolders(userID uint) ([]*domain.BookmarkFolder, error) {
+	var folders []*domain.BookmarkFolder
+	err := r.db.Where("user_id = ?", userID).Order("name").Find(&folders).Error
+	return folders, err
+}
+
+// UpdateFolder updates a bookmark folder, returning domain.ErrConflict if another folder of the user has the same name
+func (r *bookmarkRepository) UpdateFolder(folder *domain.BookmarkFolder) error {
+	var exists int64
+	err := r.db.Model(&domain.BookmarkFolder{}).
+		Where("user_id = ? AND name = ? AND id <> ?", folder.UserID, folder.Name, folder.ID).
+		Count(&exists).Error
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return domain.ErrConflict
+	}
+	return r.db.Save(folder).Error
+}
+
+// DeleteFolder deletes a bookmark folder; its bookmarks are kept without a folder by the foreign key
+func (r *bookmarkRepository) DeleteFolder(id uint) error {
+	return r.db.Delete(&domain.BookmarkFolder{}, id).Error
+}
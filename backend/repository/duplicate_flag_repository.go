@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// duplicateFlagRepository implements domain.DuplicateFlagRepository
+type duplicateFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewDuplicateFlagRepository creates a new duplicate flag repository
+func NewDuplicateFlagRepository(db *gorm.DB) domain.DuplicateFlagRepository {
+	return &duplicateFlagRepository{db: db}
+}
+
+// Create creates a new duplicate flag
+func (r *duplicateFlagRepository) Create(flag *domain.DuplicateFlag) error {
+	return r.db.Create(flag).Error
+}
+
+// GetByID retrieves a duplicate flag by ID
+func (r *duplicateFlagRepository) GetByID(id uint) (*domain.DuplicateFlag, error) {
+	var flag domain.DuplicateFlag
+	err := r.db.Preload("Image.User").Preload("MatchedImage.User").First(&flag, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// GetByStatus retrieves duplicate flags by status, oldest first
+func (r *duplicateFlagRepository) GetByStatus(status string, offset, limit int) ([]*domain.DuplicateFlag, error) {
+	var flags []*domain.DuplicateFlag
+	err := r.db.Where("status = ?", status).
+		Preload("Image.User").Preload("MatchedImage.User").
+		Offset(offset).Limit(limit).
+		Order("created_at").
+		Find(&flags).Error
+	return flags, err
+}
+
+// Update updates a duplicate flag
+func (r *duplicateFlagRepository) Update(flag *domain.DuplicateFlag) error {
+	return r.db.Omit("Image", "MatchedImage").Save(flag).Error
+}
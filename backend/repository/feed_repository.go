@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"backend/domain"
+
+	"gorm.io/gorm"
+)
+
+// feedRepository implements domain.FeedRepository
+type feedRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedRepository creates a new feed repository
+func NewFeedRepository(db *gorm.DB) domain.FeedRepository {
+	return &feedRepository{db: db}
+}
+
+// GetFeed retrieves published posts of the users userID follows, newest first. Pages are keyed on
+// (publish_at, id) rather than an offset so posts published while paging are not shown twice.
+func (r *feedRepository) GetFeed(userID uint, visibilities []string, before *domain.FeedCursor, limit int) ([]*domain.Post, error) {
+	query := r.db.Model(&domain.Post{}).
+		Joins("JOIN follows ON follows.followee_id = posts.user_id AND follows.follower_id = ?", userID).
+		Where("posts.status = ? AND posts.visibility IN ?", domain.PostStatusPublished, visibilities)
+	if before != nil {
+		query = query.Where("(posts.publish_at, posts.id) < (?, ?)", before.PublishAt, before.ID)
+	}
+
+	var posts []*domain.Post
+	err := query.
+		Preload("User").
+		Preload("Images").
+		Order("posts.publish_at DESC, posts.id DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
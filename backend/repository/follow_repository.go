@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"backend/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// followRepository implements domain.FollowRepository
+type followRepository struct {
+	db *gorm.DB
+}
+
+// NewFollowRepository creates a new follow repository
+func NewFollowRepository(db *gorm.DB) domain.FollowRepository {
+	return &followRepository{db: db}
+}
+
+// Follow records that followerID follows followeeID; following twice is a no-op
+func (r *followRepository) Follow(followerID, followeeID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.Follow{FollowerID: followerID, FolloweeID: followeeID}).Error
+}
+
+// Unfollow removes a follow
+func (r *followRepository) Unfollow(followerID, followeeID uint) error {
+	result := r.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&domain.Follow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetFollowers retrieves the users following userID, most recent first
+func (r *followRepository) GetFollowers(userID uint, offset, limit int) ([]*domain.FollowUser, error) {
+	return r.listUsers("follower_id", "followee_id", userID, offset, limit)
+}
+
+// GetFollowing retrieves the users userID follows, most recent first
+func (r *followRepository) GetFollowing(userID uint, offset, limit int) ([]*domain.FollowUser, error) {
+	return r.listUsers("followee_id", "follower_id", userID, offset, limit)
+}
+
+// listUsers lists the users on the userColumn side of userID's follows, skipping deleted accounts
+func (r *followRepository) listUsers(userColumn, keyColumn string, userID uint, offset, limit int) ([]*domain.FollowUser, error) {
+	var users []*domain.FollowUser
+	err := r.db.Table("follows").
+		Select("users.id, users.username, users.first_name, users.last_name, users.avatar, follows.created_at AS followed_at").
+		Joins("JOIN users ON users.id = follows."+userColumn+" AND users.deleted_at IS NULL").
+		Where("follows."+keyColumn+" = ?", userID).
+		Order("follows.created_at DESC").
+		Offset(offset).Limit(limit).
+		Scan(&users).Error
+	return users, err
+}
@@ -4,8 +4,10 @@ import (
 	"backend/domain"
 	"errors"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // imageRepository implements domain.ImageRepository
@@ -18,9 +20,15 @@ func NewImageRepository(db *gorm.DB) domain.ImageRepository {
 	return &imageRepository{db: db}
 }
 
-// Create creates a new image
+// Create creates a new image together with its first revision
 func (r *imageRepository) Create(image *domain.Image) error {
-	return r.db.Create(image).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		image.Revision = 1
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+		return tx.Create(domain.RevisionOf(image)).Error
+	})
 }
 
 // GetByID retrieves an image by ID
@@ -46,10 +54,10 @@ func (r *imageRepository) GetByUserID(userID uint, offset, limit int) ([]*domain
 	return images, err
 }
 
-// GetPublic retrieves public images
-func (r *imageRepository) GetPublic(offset, limit int) ([]*domain.Image, error) {
+// GetPublic retrieves images listed for the viewer; members-only images are included for logged-in viewers
+func (r *imageRepository) GetPublic(viewerID uint, offset, limit int) ([]*domain.Image, error) {
 	var images []*domain.Image
-	err := r.db.Where("is_public = ?", true).
+	err := r.db.Where("visibility IN ?", domain.ListedVisibilities(viewerID)).
 		Preload("User").
 		Offset(offset).Limit(limit).
 		Order("created_at DESC").
@@ -57,49 +65,293 @@ func (r *imageRepository) GetPublic(offset, limit int) ([]*domain.Image, error)
 	return images, err
 }
 
-// Update updates an image
+// Update writes the editable fields of an image. The file columns are left alone because
+// another request may have stored a new revision since the image was read
 func (r *imageRepository) Update(image *domain.Image) error {
-	return r.db.Save(image).Error
+	return r.db.Model(image).Select("title", "description", "tags", "visibility").Updates(image).Error
 }
 
-// Delete deletes an image
+// Delete soft-deletes an image; its files are kept until it is purged from the trash
 func (r *imageRepository) Delete(id uint) error {
 	return r.db.Delete(&domain.Image{}, id).Error
 }
 
-// Search searches images by query
-func (r *imageRepository) Search(query string, offset, limit int) ([]*domain.Image, error) {
+// Search searches public images matching the filter
+func (r *imageRepository) Search(filter domain.SearchFilter, offset, limit int) ([]*domain.Image, error) {
 	var images []*domain.Image
-	searchQuery := "%" + strings.ToLower(query) + "%"
-
-	err := r.db.Where("is_public = ? AND (LOWER(title) LIKE ? OR LOWER(description) LIKE ? OR LOWER(tags) LIKE ?)",
-		true, searchQuery, searchQuery, searchQuery).
+	err := r.db.Scopes(searchScope("images", filter)).
 		Preload("User").
 		Offset(offset).Limit(limit).
-		Order("created_at DESC").
+		Order("images.created_at DESC").
 		Find(&images).Error
 	return images, err
 }
 
-// GetByTags retrieves images by tags
+// GetByTags retrieves public images by tags
 func (r *imageRepository) GetByTags(tags []string, offset, limit int) ([]*domain.Image, error) {
-	var images []*domain.Image
+	return r.Search(domain.SearchFilter{Tags: tags}, offset, limit)
+}
+
+// Facets aggregates tags, authors and months for public images matching the filter
+func (r *imageRepository) Facets(filter domain.SearchFilter, limit int) (*domain.SearchFacets, error) {
+	return searchFacets(r.db, &domain.Image{}, "images", filter, limit)
+}
+
+// IncrementViewCount increments the view count for an image
+func (r *imageRepository) IncrementViewCount(id uint) error {
+	return r.db.Model(&domain.Image{}).Where("id = ?", id).
+		Update("view_count", gorm.Expr("view_count + 1")).Error
+}
 
-	query := r.db.Where("is_public = ?", true)
+// phashDistance is the Hamming distance between images.phash and the bound hash
+const phashDistance = "length(replace(((images.phash # ?)::bit(64))::text, '0', ''))"
 
-	for _, tag := range tags {
-		query = query.Where("LOWER(tags) LIKE ?", "%"+strings.ToLower(tag)+"%")
+// FindSimilar retrieves images with the same SHA-256 or a perceptual hash within maxDistance.
+// If viewerID is not 0, only the viewer's own images and images listed for the viewer are returned.
+func (r *imageRepository) FindSimilar(image *domain.Image, maxDistance int, viewerID uint, limit int) ([]*domain.ImageMatch, error) {
+	var conditions []string
+	var args []interface{}
+	if image.SHA256 != "" {
+		conditions = append(conditions, "images.sha256 = ?")
+		args = append(args, image.SHA256)
+	}
+	distance := "0"
+	var distanceArgs []interface{}
+	if image.PHash != nil {
+		conditions = append(conditions, "images.phash IS NOT NULL AND "+phashDistance+" <= ?")
+		args = append(args, *image.PHash, maxDistance)
+		distance = "COALESCE(" + phashDistance + ", 0)"
+		distanceArgs = append(distanceArgs, *image.PHash)
+	}
+	if len(conditions) == 0 {
+		return []*domain.ImageMatch{}, nil
 	}
 
-	err := query.Preload("User").
-		Offset(offset).Limit(limit).
-		Order("created_at DESC").
+	query := r.db.Model(&domain.Image{}).
+		Select("images.id, images.sha256, "+distance+" AS distance", distanceArgs...).
+		Where("images.id <> ?", image.ID).
+		Where("("+strings.Join(conditions, " OR ")+")", args...)
+	if viewerID != 0 {
+		query = query.Where("(images.user_id = ? OR images.visibility IN ?)", viewerID, domain.ListedVisibilities(viewerID))
+	}
+
+	var rows []struct {
+		ID       uint
+		SHA256   string `gorm:"column:sha256"`
+		Distance int
+	}
+	err := query.Order("distance, images.id").Limit(limit).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return []*domain.ImageMatch{}, err
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var images []*domain.Image
+	if err := r.db.Preload("User").Where("id IN ?", ids).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*domain.Image, len(images))
+	for _, img := range images {
+		byID[img.ID] = img
+	}
+
+	matches := make([]*domain.ImageMatch, 0, len(rows))
+	for _, row := range rows {
+		if img, ok := byID[row.ID]; ok {
+			exact := image.SHA256 != "" && row.SHA256 == image.SHA256
+			matches = append(matches, &domain.ImageMatch{Image: img, Distance: row.Distance, Exact: exact})
+		}
+	}
+	return matches, nil
+}
+
+// GetDeletedByUserID retrieves the user's images deleted after deletedAfter, newest deletion first
+func (r *imageRepository) GetDeletedByUserID(userID uint, deletedAfter time.Time, limit int) ([]*domain.Image, error) {
+	var images []*domain.Image
+	err := r.db.Unscoped().
+		Where("user_id = ? AND deleted_at >= ?", userID, deletedAfter).
+		Limit(limit).
+		Order("deleted_at DESC").
 		Find(&images).Error
 	return images, err
 }
 
-// IncrementViewCount increments the view count for an image
-func (r *imageRepository) IncrementViewCount(id uint) error {
-	return r.db.Model(&domain.Image{}).Where("id = ?", id).
-		Update("view_count", gorm.Expr("view_count + 1")).Error
+// GetDeletedByID retrieves a soft-deleted image by ID
+func (r *imageRepository) GetDeletedByID(id uint) (*domain.Image, error) {
+	var image domain.Image
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&image, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+// Restore clears the deletion of a soft-deleted image
+func (r *imageRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&domain.Image{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDeleted hard-deletes up to limit images deleted before deletedBefore. Each image is
+// removed in its own transaction together with releasing the blobs of all its revisions, or
+// queueing deletion of the files of revisions that predate blob storage.
+func (r *imageRepository) PurgeDeleted(deletedBefore time.Time, limit int) (int, error) {
+	var images []*domain.Image
+	err := r.db.Unscoped().
+		Where("deleted_at < ?", deletedBefore).
+		Limit(limit).
+		Order("deleted_at").
+		Find(&images).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, image := range images {
+		deleted := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			// Skip the image if it was restored in the meantime
+			result := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("deleted_at < ?", deletedBefore).
+				Limit(1).Find(&domain.Image{}, image.ID)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var revisions []*domain.ImageRevision
+			if err := tx.Where("image_id = ?", image.ID).Find(&revisions).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec("DELETE FROM post_images WHERE image_id = ?", image.ID).Error; err != nil {
+				return err
+			}
+			// Revisions are removed by the foreign key cascade
+			if err := tx.Unscoped().Delete(&domain.Image{}, image.ID).Error; err != nil {
+				return err
+			}
+			deleted = true
+
+			for _, revision := range revisions {
+				if revision.SHA256 == "" {
+					if err := enqueueImageDeletes(tx, revision.CloudinaryID, revision.Variants); err != nil {
+						return err
+					}
+					continue
+				}
+				// Nothing to release is not a reason to block purging the image
+				if _, err := releaseBlob(tx, revision.SHA256); err != nil && !errors.Is(err, domain.ErrNotFound) {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+		// Count only once the transaction has committed
+		if deleted {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// GetRevisions retrieves the revisions of an image, newest first
+func (r *imageRepository) GetRevisions(imageID uint) ([]*domain.ImageRevision, error) {
+	var revisions []*domain.ImageRevision
+	err := r.db.Where("image_id = ?", imageID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// GetRevision retrieves a single revision of an image
+func (r *imageRepository) GetRevision(imageID uint, revision int) (*domain.ImageRevision, error) {
+	var rev domain.ImageRevision
+	err := r.db.Where("image_id = ? AND revision = ?", imageID, revision).First(&rev).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// AddRevision stores revision as the next revision of image and makes it the image's current file.
+// The image row is locked so concurrent replacements get distinct revision numbers.
+func (r *imageRepository) AddRevision(image *domain.Image, revision *domain.ImageRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current domain.Image
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, image.ID).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+
+		var latest int
+		err = tx.Model(&domain.ImageRevision{}).
+			Where("image_id = ?", image.ID).
+			Select("COALESCE(MAX(revision), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+
+		revision.ImageID = image.ID
+		revision.Revision = latest + 1
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		image.ApplyRevision(revision)
+		return updateCurrentFile(tx, image)
+	})
+}
+
+// SetRevision makes an existing revision the image's current file
+func (r *imageRepository) SetRevision(image *domain.Image, revision *domain.ImageRevision) error {
+	image.ApplyRevision(revision)
+	return updateCurrentFile(r.db, image)
+}
+
+// updateCurrentFile writes the file columns of image without touching its title, tags or stats
+func updateCurrentFile(db *gorm.DB, image *domain.Image) error {
+	result := db.Model(&domain.Image{}).Where("id = ?", image.ID).Updates(map[string]interface{}{
+		"revision":      image.Revision,
+		"cloudinary_id": image.CloudinaryID,
+		"url":           image.URL,
+		"width":         image.Width,
+		"height":        image.Height,
+		"file_size":     image.FileSize,
+		"format":        image.Format,
+		"sha256":        image.SHA256,
+		"phash":         image.PHash,
+		"variants":      image.Variants,
+		"metadata":      image.Metadata,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"backend/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// importRepository implements domain.ImportRepository
+type importRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository creates a new import repository
+func NewImportRepository(db *gorm.DB) domain.ImportRepository {
+	return &importRepository{db: db}
+}
+
+// Exists checks whether an item from the source has already been imported
+func (r *importRepository) Exists(source, externalID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ImportedItem{}).
+		Where("source = ? AND external_id = ?", source, externalID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Save creates the post with its images, moves the images' timestamps back to the post's
+// and records the import, all in one transaction
+func (r *importRepository) Save(item *domain.ImportedItem, post *domain.Post, imageIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(post).Error; err != nil {
+			return err
+		}
+
+		for _, imageID := range imageIDs {
+			err := tx.Exec("INSERT INTO post_images (post_id, image_id) VALUES (?, ?)", post.ID, imageID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(imageIDs) > 0 {
+			if err := backdateImages(tx, imageIDs, post.CreatedAt); err != nil {
+				return err
+			}
+		}
+
+		item.PostID = post.ID
+		return tx.Create(item).Error
+	})
+}
+
+// BackdateImage sets an image's timestamps to when it was originally made
+func (r *importRepository) BackdateImage(imageID uint, at time.Time) error {
+	return backdateImages(r.db, []uint{imageID}, at)
+}
+
+// backdateImages sets the images' timestamps without touching anything else
+func backdateImages(db *gorm.DB, imageIDs []uint, at time.Time) error {
+	return db.Model(&domain.Image{}).Where("id IN ?", imageIDs).UpdateColumns(map[string]interface{}{
+		"created_at": at,
+		"updated_at": at,
+	}).Error
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"backend/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// notificationRepository implements domain.NotificationRepository
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) domain.NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Record stores a notification. While the recipient has an unread notification of the same type
+// about the same target, the event is folded into it: the actor becomes the latest one and the
+// actor count goes up once per distinct user.
+func (r *notificationRepository) Record(event domain.NotificationEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// The no-op update makes RETURNING yield the existing row on conflict
+		var id uint
+		err := tx.Raw(`INSERT INTO notifications (user_id, type, target_type, target_id, actor_count, created_at, updated_at)
+			VALUES (?, ?, ?, ?, 0, ?, ?)
+			ON CONFLICT (user_id, type, target_type, target_id) WHERE read_at IS NULL
+			DO UPDATE SET user_id = notifications.user_id
+			RETURNING id`,
+			event.RecipientID, event.Type, event.TargetType, event.TargetID, now, now).Scan(&id).Error
+		if err != nil {
+			return err
+		}
+
+		if event.ActorID == 0 {
+			return tx.Model(&domain.Notification{}).Where("id = ?", id).Update("updated_at", now).Error
+		}
+
+		result := tx.Exec(`INSERT INTO notification_actors (notification_id, actor_id) VALUES (?, ?)
+			ON CONFLICT DO NOTHING`, id, event.ActorID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			// The same user acting again is not news
+			return result.Error
+		}
+
+		return tx.Model(&domain.Notification{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"actor_id":    event.ActorID,
+			"actor_count": gorm.Expr("actor_count + 1"),
+			"updated_at":  now,
+		}).Error
+	})
+}
+
+// GetByUserID retrieves the user's notifications, most recently updated first
+func (r *notificationRepository) GetByUserID(userID uint, unreadOnly bool, offset, limit int) ([]*domain.Notification, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []*domain.Notification
+	err := query.
+		Preload("Actor", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "first_name", "last_name", "avatar")
+		}).
+		Order("updated_at DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// CountUnread counts the user's unread notifications
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications as read
+func (r *notificationRepository) MarkRead(userID, id uint) error {
+	var count int64
+	err := r.db.Model(&domain.Notification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+
+	return r.db.Model(&domain.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead marks all of the user's notifications as read
+func (r *notificationRepository) MarkAllRead(userID uint) error {
+	return r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// GetPreferences retrieves the notification types the user has configured
+func (r *notificationRepository) GetPreferences(userID uint) ([]*domain.NotificationPreference, error) {
+	var prefs []*domain.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+// SavePreferences creates or updates notification preferences
+func (r *notificationRepository) SavePreferences(prefs []*domain.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+	}).Create(&prefs).Error
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"backend/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// outboxRepository implements domain.OutboxRepository
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new storage outbox repository
+func NewOutboxRepository(db *gorm.DB) domain.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Enqueue records storage tasks to be run by the outbox worker
+func (r *outboxRepository) Enqueue(tasks ...*domain.OutboxTask) error {
+	return enqueueTasks(r.db, tasks...)
+}
+
+// Claim retrieves due tasks and pushes their next attempt back by lease,
+// so that other workers skip them while they are being processed
+func (r *outboxRepository) Claim(now time.Time, lease time.Duration, limit int) ([]*domain.OutboxTask, error) {
+	var tasks []*domain.OutboxTask
+	err := r.db.Raw(`UPDATE storage_outbox SET next_attempt_at = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM storage_outbox
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), domain.OutboxStatusPending, now, limit).
+		Scan(&tasks).Error
+	return tasks, err
+}
+
+// Complete deletes a finished task
+func (r *outboxRepository) Complete(id uint) error {
+	return r.db.Delete(&domain.OutboxTask{}, id).Error
+}
+
+// Update updates a task
+func (r *outboxRepository) Update(task *domain.OutboxTask) error {
+	return r.db.Save(task).Error
+}
+
+// PendingPublicIDs retrieves public IDs that are already queued for deletion
+func (r *outboxRepository) PendingPublicIDs() ([]string, error) {
+	var ids []string
+	err := r.db.Model(&domain.OutboxTask{}).
+		Where("action = ? AND status = ?", domain.OutboxActionDeleteImage, domain.OutboxStatusPending).
+		Pluck("public_id", &ids).Error
+	return ids, err
+}
+
+// referencedPublicIDs selects every public ID referenced by blobs, images or image revisions,
+// including variants. Images in the trash still reference their files until they are purged.
+const referencedPublicIDs = `SELECT cloudinary_id AS public_id FROM blobs
+	UNION SELECT v.value->>'cloudinary_id' FROM blobs CROSS JOIN LATERAL jsonb_each(blobs.variants) AS v
+	UNION SELECT cloudinary_id FROM images
+	UNION SELECT v.value->>'cloudinary_id' FROM images CROSS JOIN LATERAL jsonb_each(images.variants) AS v
+	UNION SELECT cloudinary_id FROM image_revisions
+	UNION SELECT v.value->>'cloudinary_id' FROM image_revisions CROSS JOIN LATERAL jsonb_each(image_revisions.variants) AS v`
+
+// ReferencedPublicIDs retrieves every public ID referenced by blobs, images or image revisions
+func (r *outboxRepository) ReferencedPublicIDs() ([]string, error) {
+	var ids []string
+	err := r.db.Raw(referencedPublicIDs).Scan(&ids).Error
+	return ids, err
+}
+
+// IsReferenced reports whether a blob, image or image revision references the public ID
+func (r *outboxRepository) IsReferenced(publicID string) (bool, error) {
+	var referenced bool
+	err := r.db.Raw("SELECT EXISTS (SELECT 1 FROM ("+referencedPublicIDs+") AS refs WHERE refs.public_id = ?)", publicID).
+		Scan(&referenced).Error
+	return referenced, err
+}
+
+// enqueueTasks inserts tasks using db, which may be a transaction
+func enqueueTasks(db *gorm.DB, tasks ...*domain.OutboxTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		if task.Status == "" {
+			task.Status = domain.OutboxStatusPending
+		}
+		if task.NextAttemptAt.IsZero() {
+			task.NextAttemptAt = now
+		}
+	}
+	return db.Create(tasks).Error
+}
+
+// enqueueImageDeletes queues deletion of a stored image and its variants
+func enqueueImageDeletes(db *gorm.DB, publicID string, variants domain.ImageVariants) error {
+	tasks := []*domain.OutboxTask{{Action: domain.OutboxActionDeleteImage, PublicID: publicID}}
+	for _, variant := range variants {
+		tasks = append(tasks, &domain.OutboxTask{Action: domain.OutboxActionDeleteImage, PublicID: variant.CloudinaryID})
+	}
+	return enqueueTasks(db, tasks...)
+}
@@ -3,9 +3,10 @@ package repository
 import (
 	"backend/domain"
 	"errors"
-	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // postRepository implements domain.PostRepository
@@ -18,15 +19,17 @@ func NewPostRepository(db *gorm.DB) domain.PostRepository {
 	return &postRepository{db: db}
 }
 
-// Create creates a new post
+// Create creates a new post together with its stages
 func (r *postRepository) Create(post *domain.Post) error {
 	return r.db.Create(post).Error
 }
 
-// GetByID retrieves a post by ID
+// GetByID retrieves a post by ID, with its stages in order
 func (r *postRepository) GetByID(id uint) (*domain.Post, error) {
 	var post domain.Post
-	err := r.db.Preload("User").Preload("Images").First(&post, id).Error
+	err := r.db.Preload("User").Preload("Images").
+		Preload("Stages", orderedStages).Preload("Stages.Image").
+		First(&post, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound
@@ -47,21 +50,32 @@ func (r *postRepository) GetByUserID(userID uint, offset, limit int) ([]*domain.
 	return posts, err
 }
 
-// GetPublic retrieves public posts
-func (r *postRepository) GetPublic(offset, limit int) ([]*domain.Post, error) {
+// GetPublic retrieves published posts listed for the viewer, most recently published first
+func (r *postRepository) GetPublic(viewerID uint, offset, limit int) ([]*domain.Post, error) {
 	var posts []*domain.Post
-	err := r.db.Where("is_public = ?", true).
+	err := r.db.Where("visibility IN ? AND status = ?", domain.ListedVisibilities(viewerID), domain.PostStatusPublished).
 		Preload("User").
 		Preload("Images").
 		Offset(offset).Limit(limit).
-		Order("created_at DESC").
+		Order("publish_at DESC").
 		Find(&posts).Error
 	return posts, err
 }
 
-// Update updates a post
-func (r *postRepository) Update(post *domain.Post) error {
-	return r.db.Save(post).Error
+// Update writes the editable fields and publication of a post, provided its status is still
+// fromStatus. If the scheduler published it in the meantime, ErrConflict is returned instead of
+// writing the old status back. Stages are only changed through ReplaceStages
+func (r *postRepository) Update(post *domain.Post, fromStatus string) error {
+	result := r.db.Model(post).Where("status = ?", fromStatus).
+		Select("title", "description", "tags", "visibility", "status", "publish_at").
+		Updates(post)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
 }
 
 // Delete deletes a post
@@ -69,41 +83,153 @@ func (r *postRepository) Delete(id uint) error {
 	return r.db.Delete(&domain.Post{}, id).Error
 }
 
-// Search searches posts by query
-func (r *postRepository) Search(query string, offset, limit int) ([]*domain.Post, error) {
+// Search searches public posts matching the filter
+func (r *postRepository) Search(filter domain.SearchFilter, offset, limit int) ([]*domain.Post, error) {
 	var posts []*domain.Post
-	searchQuery := "%" + strings.ToLower(query) + "%"
-
-	err := r.db.Where("is_public = ? AND (LOWER(title) LIKE ? OR LOWER(description) LIKE ? OR LOWER(tags) LIKE ?)",
-		true, searchQuery, searchQuery, searchQuery).
+	err := r.db.Scopes(searchScope("posts", filter)).
 		Preload("User").
 		Preload("Images").
 		Offset(offset).Limit(limit).
-		Order("created_at DESC").
+		Order("posts.publish_at DESC, posts.id DESC").
 		Find(&posts).Error
 	return posts, err
 }
 
-// GetByTags retrieves posts by tags
+// GetByTags retrieves public posts by tags
 func (r *postRepository) GetByTags(tags []string, offset, limit int) ([]*domain.Post, error) {
-	var posts []*domain.Post
+	return r.Search(domain.SearchFilter{Tags: tags}, offset, limit)
+}
 
-	query := r.db.Where("is_public = ?", true)
+// Facets aggregates tags, authors and months for public posts matching the filter
+func (r *postRepository) Facets(filter domain.SearchFilter, limit int) (*domain.SearchFacets, error) {
+	return searchFacets(r.db, &domain.Post{}, "posts", filter, limit)
+}
 
-	for _, tag := range tags {
-		query = query.Where("LOWER(tags) LIKE ?", "%"+strings.ToLower(tag)+"%")
-	}
+// IncrementViewCount increments the view count for a post
+func (r *postRepository) IncrementViewCount(id uint) error {
+	return r.db.Model(&domain.Post{}).Where("id = ?", id).
+		Update("view_count", gorm.Expr("view_count + 1")).Error
+}
 
-	err := query.Preload("User").
+// GetDeletedByUserID retrieves the user's posts deleted after deletedAfter, newest deletion first
+func (r *postRepository) GetDeletedByUserID(userID uint, deletedAfter time.Time, limit int) ([]*domain.Post, error) {
+	var posts []*domain.Post
+	err := r.db.Unscoped().
+		Where("user_id = ? AND deleted_at >= ?", userID, deletedAfter).
 		Preload("Images").
-		Offset(offset).Limit(limit).
-		Order("created_at DESC").
+		Limit(limit).
+		Order("deleted_at DESC").
 		Find(&posts).Error
 	return posts, err
 }
 
-// IncrementViewCount increments the view count for a post
-func (r *postRepository) IncrementViewCount(id uint) error {
-	return r.db.Model(&domain.Post{}).Where("id = ?", id).
-		Update("view_count", gorm.Expr("view_count + 1")).Error
+// GetDeletedByID retrieves a soft-deleted post by ID
+func (r *postRepository) GetDeletedByID(id uint) (*domain.Post, error) {
+	var post domain.Post
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").Preload("Images").First(&post, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// Restore clears the deletion of a soft-deleted post
+func (r *postRepository) Restore(id uint) error {
+	result := r.db.Unscoped().Model(&domain.Post{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDeleted hard-deletes up to limit posts deleted before deletedBefore, with their image associations
+func (r *postRepository) PurgeDeleted(deletedBefore time.Time, limit int) (int, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&domain.Post{}).
+		Where("deleted_at < ?", deletedBefore).
+		Limit(limit).
+		Order("deleted_at").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		deleted := false
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			// Skip the post if it was restored in the meantime
+			result := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("deleted_at < ?", deletedBefore).
+				Limit(1).Find(&domain.Post{}, id)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			if err := tx.Exec("DELETE FROM post_images WHERE post_id = ?", id).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&domain.Post{}, id).Error; err != nil {
+				return err
+			}
+			deleted = true
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+		// Count only once the transaction has committed
+		if deleted {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// ReplaceStages replaces all stages of a post, numbering them in the given order
+func (r *postRepository) ReplaceStages(postID uint, stages []domain.PostStage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", postID).Delete(&domain.PostStage{}).Error; err != nil {
+			return err
+		}
+		if len(stages) == 0 {
+			return nil
+		}
+		for i := range stages {
+			stages[i].PostID = postID
+			stages[i].Position = i
+		}
+		return tx.Omit("Image").Create(&stages).Error
+	})
+}
+
+// orderedStages orders preloaded stages by position
+func orderedStages(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// MarkAnnounced records that a post has been announced, unless it already was. Only the caller
+// that gets true announces it, so it is announced once even when published concurrently
+func (r *postRepository) MarkAnnounced(postID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.Post{}).
+		Where("id = ? AND announced_at IS NULL", postID).
+		Update("announced_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// PublishDue marks scheduled posts whose publish time has passed as published and returns them
+func (r *postRepository) PublishDue(now time.Time) ([]*domain.Post, error) {
+	var posts []*domain.Post
+	err := r.db.Model(&posts).Clauses(clause.Returning{}).
+		Where("status = ? AND publish_at <= ?", domain.PostStatusScheduled, now).
+		Update("status", domain.PostStatusPublished).Error
+	return posts, err
 }
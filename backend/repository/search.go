@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"backend/domain"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// searchScope applies the shared search filter to a public images/posts query.
+// Columns are qualified with the table name so the scope can be combined with joins.
+func searchScope(table string, filter domain.SearchFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where(table+".visibility IN ?", domain.ListedVisibilities(filter.ViewerID))
+		if table == "posts" {
+			// Drafts and scheduled posts are never listed
+			db = db.Where("posts.status = ?", domain.PostStatusPublished)
+		}
+
+		if filter.Query != "" {
+			searchQuery := "%" + strings.ToLower(filter.Query) + "%"
+			db = db.Where("(LOWER("+table+".title) LIKE ? OR LOWER("+table+".description) LIKE ? OR LOWER("+table+".tags) LIKE ?)",
+				searchQuery, searchQuery, searchQuery)
+		}
+
+		for _, tag := range filter.Tags {
+			db = db.Where("LOWER("+table+".tags) LIKE ?", "%"+strings.ToLower(tag)+"%")
+		}
+
+		if filter.AuthorID != 0 {
+			db = db.Where(table+".user_id = ?", filter.AuthorID)
+		}
+
+		if month, err := time.Parse("2006-01", filter.Month); err == nil {
+			column := dateColumn(table)
+			db = db.Where(column+" >= ? AND "+column+" < ?", month, month.AddDate(0, 1, 0))
+		}
+
+		return db
+	}
+}
+
+// dateColumn is the column that dates rows of table in listings: posts by when they were
+// published, so that scheduled posts sort and group the same way as in /public/posts
+func dateColumn(table string) string {
+	if table == "posts" {
+		return "posts.publish_at"
+	}
+	return table + ".created_at"
+}
+
+// searchFacets aggregates tags, authors and months for rows matching the filter.
+// model must be the gorm model for table so that soft-deleted rows are excluded.
+func searchFacets(db *gorm.DB, model interface{}, table string, filter domain.SearchFilter, limit int) (*domain.SearchFacets, error) {
+	facets := &domain.SearchFacets{
+		Tags:    []domain.FacetCount{},
+		Authors: []domain.FacetCount{},
+		Months:  []domain.FacetCount{},
+	}
+
+	err := db.Model(model).Scopes(searchScope(table, filter)).
+		Joins("CROSS JOIN LATERAL unnest(string_to_array(" + table + ".tags, ',')) AS tag").
+		Where("TRIM(tag) <> ''").
+		Select("LOWER(TRIM(tag)) AS value, COUNT(*) AS count").
+		Group("LOWER(TRIM(tag))").
+		Order("count DESC, value").
+		Limit(limit).
+		Scan(&facets.Tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model(model).Scopes(searchScope(table, filter)).
+		Joins("JOIN users ON users.id = " + table + ".user_id").
+		Select("CAST(" + table + ".user_id AS TEXT) AS value, users.username AS label, COUNT(*) AS count").
+		Group(table + ".user_id, users.username").
+		Order("count DESC, label").
+		Limit(limit).
+		Scan(&facets.Authors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model(model).Scopes(searchScope(table, filter)).
+		Select("TO_CHAR(DATE_TRUNC('month', " + dateColumn(table) + "), 'YYYY-MM') AS value, COUNT(*) AS count").
+		Group("value").
+		Order("value DESC").
+		Scan(&facets.Months).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
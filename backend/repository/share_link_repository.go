@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// shareLinkRepository implements domain.ShareLinkRepository
+type shareLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewShareLinkRepository creates a new share link repository
+func NewShareLinkRepository(db *gorm.DB) domain.ShareLinkRepository {
+	return &shareLinkRepository{db: db}
+}
+
+// Create creates a new share link
+func (r *shareLinkRepository) Create(share *domain.ShareLink) error {
+	return r.db.Create(share).Error
+}
+
+// GetByID retrieves a share link by ID
+func (r *shareLinkRepository) GetByID(id uint) (*domain.ShareLink, error) {
+	var share domain.ShareLink
+	err := r.db.First(&share, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &share, nil
+}
+
+// GetByTarget retrieves the share links of an image or post, newest first
+func (r *shareLinkRepository) GetByTarget(targetType string, targetID uint) ([]*domain.ShareLink, error) {
+	var shares []*domain.ShareLink
+	err := r.db.Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Order("created_at DESC").
+		Find(&shares).Error
+	return shares, err
+}
+
+// Revoke marks a share link as revoked; revoking twice keeps the first time
+func (r *shareLinkRepository) Revoke(id uint, now time.Time) error {
+	return r.db.Model(&domain.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// RecordView increments the view count of a share link that is neither revoked nor expired
+func (r *shareLinkRepository) RecordView(id uint, now time.Time) (*domain.ShareLink, error) {
+	var shares []*domain.ShareLink
+	result := r.db.Model(&shares).Clauses(clause.Returning{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, now).
+		Update("view_count", gorm.Expr("view_count + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(shares) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return shares[0], nil
+}
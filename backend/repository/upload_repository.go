@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// uploadRepository implements domain.UploadRepository
+type uploadRepository struct {
+	db *gorm.DB
+}
+
+// NewUploadRepository creates a new upload repository
+func NewUploadRepository(db *gorm.DB) domain.UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+// Create creates a new upload
+func (r *uploadRepository) Create(upload *domain.Upload) error {
+	return r.db.Create(upload).Error
+}
+
+// GetByID retrieves an upload by ID
+func (r *uploadRepository) GetByID(id string) (*domain.Upload, error) {
+	var upload domain.Upload
+	err := r.db.Where("id = ?", id).First(&upload).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Update updates an upload
+func (r *uploadRepository) Update(upload *domain.Upload) error {
+	return r.db.Save(upload).Error
+}
+
+// Delete deletes an upload
+func (r *uploadRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&domain.Upload{}).Error
+}
+
+// Lock claims the upload until the given time unless a request on any server holds it. The
+// column is not mapped on domain.Upload, so Update never overwrites someone else's claim
+func (r *uploadRepository) Lock(id string, now, until time.Time) (bool, error) {
+	result := r.db.Model(&domain.Upload{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until <= ?)", id, now).
+		Update("locked_until", until)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Unlock releases a claim taken with Lock
+func (r *uploadRepository) Unlock(id string) error {
+	return r.db.Model(&domain.Upload{}).Where("id = ?", id).Update("locked_until", nil).Error
+}
+
+// GetExpired retrieves uploads that expired before now
+func (r *uploadRepository) GetExpired(now time.Time, limit int) ([]*domain.Upload, error) {
+	var uploads []*domain.Upload
+	err := r.db.Where("expires_at < ?", now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"backend/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// webhookRepository implements domain.WebhookRepository
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) domain.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// Create creates a new webhook
+func (r *webhookRepository) Create(webhook *domain.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+// GetByID retrieves a webhook by ID
+func (r *webhookRepository) GetByID(id uint) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.First(&webhook, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// GetAll retrieves every webhook
+func (r *webhookRepository) GetAll() ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	err := r.db.Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetActive retrieves the enabled webhooks
+func (r *webhookRepository) GetActive() ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	err := r.db.Where("active").Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Update updates a webhook
+func (r *webhookRepository) Update(webhook *domain.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete deletes a webhook; its deliveries are removed by the foreign key
+func (r *webhookRepository) Delete(id uint) error {
+	result := r.db.Delete(&domain.Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries records deliveries to be sent by the delivery worker
+func (r *webhookRepository) EnqueueDeliveries(deliveries ...*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, delivery := range deliveries {
+		if delivery.Status == "" {
+			delivery.Status = domain.WebhookDeliveryPending
+		}
+		if delivery.NextAttemptAt.IsZero() {
+			delivery.NextAttemptAt = now
+		}
+	}
+	return r.db.Create(deliveries).Error
+}
+
+// ClaimDeliveries retrieves due deliveries and pushes their next attempt back by lease,
+// so that other workers skip them while they are being sent
+func (r *webhookRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := r.db.Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), domain.WebhookDeliveryPending, now, limit).
+		Scan(&deliveries).Error
+	return deliveries, err
+}
+
+// UpdateDelivery updates a delivery
+func (r *webhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// GetDeliveries retrieves a webhook's deliveries, newest first
+func (r *webhookRepository) GetDeliveries(webhookID uint, offset, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := r.db.Where("webhook_id = ?", webhookID).
+		Order("created_at DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// PurgeDeliveries deletes finished deliveries created before the given time
+func (r *webhookRepository) PurgeDeliveries(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ? AND status <> ?", before, domain.WebhookDeliveryPending).
+		Delete(&domain.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
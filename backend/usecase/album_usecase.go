@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"backend/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+// アルバムの入力の上限
+const (
+	maxAlbumItems    = 500 // 1つのアルバムに入れられる作品数
+	maxAlbumTitleLen = 100 // タイトルの文字数
+)
+
+// albumUseCase
+// @description: アルバムユースケースの実装
+type albumUseCase struct {
+	albumRepo domain.AlbumRepository
+	imageRepo domain.ImageRepository
+	postRepo  domain.PostRepository
+	userRepo  domain.UserRepository
+}
+
+// NewAlbumUseCase
+// @description: アルバムユースケースを初期化
+func NewAlbumUseCase(
+	albumRepo domain.AlbumRepository,
+	imageRepo domain.ImageRepository,
+	postRepo domain.PostRepository,
+	userRepo domain.UserRepository,
+) domain.AlbumUseCase {
+	return &albumUseCase{
+		albumRepo: albumRepo,
+		imageRepo: imageRepo,
+		postRepo:  postRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// CreateAlbum
+// @description: アルバムを作成（visibilityが空の場合はpublic）
+func (u *albumUseCase) CreateAlbum(userID uint, title, description, visibility string) (*domain.Album, error) {
+	if visibility == "" {
+		visibility = domain.VisibilityPublic
+	}
+	title = strings.TrimSpace(title)
+	if !validAlbumTitle(title) || !domain.ValidVisibility(visibility) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	album := &domain.Album{
+		UserID:      userID,
+		Title:       title,
+		Description: description,
+		Visibility:  visibility,
+	}
+
+	err := u.albumRepo.Create(album)
+	if err != nil {
+		return nil, err
+	}
+
+	return album, nil
+}
+
+// GetAlbum
+// @description: アルバムをIDで取得（閲覧できないアルバムは存在しないものとして扱う）
+func (u *albumUseCase) GetAlbum(viewerID, albumID uint) (*domain.Album, error) {
+	album, err := u.albumRepo.GetByID(albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !album.VisibleTo(viewerID) {
+		return nil, domain.ErrNotFound
+	}
+	hideInvisibleAlbumItems(album, viewerID)
+
+	return album, nil
+}
+
+// GetMyAlbums
+// @description: 自分のアルバムをすべての公開範囲で取得
+func (u *albumUseCase) GetMyAlbums(userID uint, page, limit int) ([]*domain.Album, error) {
+	offset := (page - 1) * limit
+	return u.albumRepo.GetByUserID(userID, nil, offset, limit)
+}
+
+// GetUserAlbums
+// @description: ユーザーのアルバムのうち閲覧者の一覧に出るものを取得（本人はすべて）
+func (u *albumUseCase) GetUserAlbums(viewerID uint, username string, page, limit int) ([]*domain.Album, error) {
+	user, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	var visibilities []string
+	if viewerID == 0 || viewerID != user.ID {
+		visibilities = domain.ListedVisibilities(viewerID)
+	}
+
+	offset := (page - 1) * limit
+	albums, err := u.albumRepo.GetByUserID(user.ID, visibilities, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, album := range albums {
+		if album.CoverImage != nil && !album.CoverImage.VisibleTo(viewerID) {
+			album.CoverImage = nil
+		}
+	}
+	return albums, nil
+}
+
+// GetUserAlbum
+// @description: ユーザーのアルバムを取得（URLのユーザー名とアルバムの所有者が違う場合は存在しないものとして扱う）
+func (u *albumUseCase) GetUserAlbum(viewerID uint, username string, albumID uint) (*domain.Album, error) {
+	album, err := u.GetAlbum(viewerID, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if album.User.Username != username {
+		return nil, domain.ErrNotFound
+	}
+
+	return album, nil
+}
+
+// UpdateAlbum
+// @description: アルバムを更新（visibilityが空なら公開範囲は変えない。coverImageIDがnilなら表紙を外す）
+func (u *albumUseCase) UpdateAlbum(userID, albumID uint, title, description, visibility string, coverImageID *uint) (*domain.Album, error) {
+	title = strings.TrimSpace(title)
+	if !validAlbumTitle(title) || (visibility != "" && !domain.ValidVisibility(visibility)) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	album, err := u.ownAlbum(userID, albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if coverImageID != nil {
+		// 表紙も作品を入れるときと同じ条件で選べる
+		if _, err := u.addableImage(userID, *coverImageID); err != nil {
+			return nil, err
+		}
+	}
+
+	album.Title = title
+	album.Description = description
+	if visibility != "" {
+		album.Visibility = visibility
+	}
+	album.CoverImageID = coverImageID
+
+	err = u.albumRepo.Update(album)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetAlbum(userID, albumID)
+}
+
+// DeleteAlbum
+// @description: アルバムを削除（中の作品自体は削除しない）
+func (u *albumUseCase) DeleteAlbum(userID, albumID uint) error {
+	if _, err := u.ownAlbum(userID, albumID); err != nil {
+		return err
+	}
+	return u.albumRepo.Delete(albumID)
+}
+
+// AddAlbumItem
+// @description: 作品をアルバムの末尾に追加する。自分の作品はどれでも、
+// 他のユーザーの作品は自分の一覧に出るもの（公開・メンバー限定）だけを入れられる
+func (u *albumUseCase) AddAlbumItem(userID, albumID uint, itemType string, itemID uint) (*domain.Album, error) {
+	album, err := u.ownAlbum(userID, albumID)
+	if err != nil {
+		return nil, err
+	}
+	if album.ItemCount >= maxAlbumItems {
+		return nil, domain.ErrInvalidInput
+	}
+
+	item := &domain.AlbumItem{AlbumID: albumID, ItemType: itemType}
+	switch itemType {
+	case domain.AlbumItemImage:
+		image, err := u.addableImage(userID, itemID)
+		if err != nil {
+			return nil, err
+		}
+		item.ImageID = &image.ID
+	case domain.AlbumItemPost:
+		post, err := u.postRepo.GetByID(itemID)
+		if err != nil {
+			return nil, err
+		}
+		if !addable(post.Visibility, post.UserID, userID) || post.Status != domain.PostStatusPublished {
+			return nil, domain.ErrNotFound
+		}
+		item.PostID = &post.ID
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+
+	err = u.albumRepo.AddItem(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetAlbum(userID, albumID)
+}
+
+// RemoveAlbumItem
+// @description: 作品をアルバムから取り除く
+func (u *albumUseCase) RemoveAlbumItem(userID, albumID, itemID uint) (*domain.Album, error) {
+	if _, err := u.ownAlbum(userID, albumID); err != nil {
+		return nil, err
+	}
+
+	err := u.albumRepo.RemoveItem(albumID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetAlbum(userID, albumID)
+}
+
+// ReorderAlbumItems
+// @description: アルバムの作品を指定した順番に並べ替える（アルバムのすべての作品のIDを指定する）
+func (u *albumUseCase) ReorderAlbumItems(userID, albumID uint, itemIDs []uint) (*domain.Album, error) {
+	if _, err := u.ownAlbum(userID, albumID); err != nil {
+		return nil, err
+	}
+
+	err := u.albumRepo.ReorderItems(albumID, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.GetAlbum(userID, albumID)
+}
+
+// ownAlbum
+// @description: ユーザーが所有するアルバムを取得
+func (u *albumUseCase) ownAlbum(userID, albumID uint) (*domain.Album, error) {
+	album, err := u.albumRepo.GetByID(albumID)
+	if err != nil {
+		return nil, err
+	}
+
+	if album.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	return album, nil
+}
+
+// addableImage
+// @description: アルバムに入れられる画像を取得（入れられない画像は存在しないものとして扱う）
+func (u *albumUseCase) addableImage(userID, imageID uint) (*domain.Image, error) {
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !addable(image.Visibility, image.UserID, userID) {
+		return nil, domain.ErrNotFound
+	}
+
+	return image, nil
+}
+
+// addable
+// @description: アルバムの所有者が作品を入れられるか（自分の作品か、自分の一覧に出る作品）
+func addable(visibility string, ownerID, albumOwnerID uint) bool {
+	if ownerID == albumOwnerID {
+		return true
+	}
+	for _, listed := range domain.ListedVisibilities(albumOwnerID) {
+		if visibility == listed {
+			return true
+		}
+	}
+	return false
+}
+
+// hideInvisibleAlbumItems
+// @description: アルバムの作品のうち閲覧者が見られないもの・削除されたものを除き、表紙がなければ最初の画像を表紙にする。
+// 他のユーザーの作品は、後から限定公開にされた場合も一覧に出ないものとして除く
+func hideInvisibleAlbumItems(album *domain.Album, viewerID uint) {
+	visible := func(visibility string, ownerID uint) bool {
+		return domain.CanView(visibility, ownerID, viewerID) &&
+			(ownerID == album.UserID || visibility != domain.VisibilityUnlisted)
+	}
+
+	items := album.Items[:0]
+	for _, item := range album.Items {
+		switch {
+		case item.Image != nil && visible(item.Image.Visibility, item.Image.UserID):
+		case item.Post != nil && item.Post.VisibleTo(viewerID) && visible(item.Post.Visibility, item.Post.UserID):
+			hideInvisibleImages(item.Post, viewerID)
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	album.Items = items
+	album.ItemCount = len(items)
+
+	if album.CoverImage != nil && !visible(album.CoverImage.Visibility, album.CoverImage.UserID) {
+		album.CoverImage = nil
+	}
+	if album.CoverImage == nil {
+		for _, item := range album.Items {
+			if item.Image != nil {
+				album.CoverImage = item.Image
+				break
+			}
+		}
+	}
+}
+
+// validAlbumTitle
+// @description: アルバムのタイトルが空でなく長すぎないか
+func validAlbumTitle(title string) bool {
+	return title != "" && utf8.RuneCountInString(title) <= maxAlbumTitleLen
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"backend/domain"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBookmarkFolderNameLen
+// @description: ブックマークのフォルダ名の文字数の上限
+const maxBookmarkFolderNameLen = 50
+
+// bookmarkUseCase
+// @description: ブックマークユースケースの実装
+type bookmarkUseCase struct {
+	bookmarkRepo domain.BookmarkRepository
+	imageRepo    domain.ImageRepository
+	postRepo     domain.PostRepository
+}
+
+// NewBookmarkUseCase
+// @description: ブックマークユースケースを初期化
+func NewBookmarkUseCase(bookmarkRepo domain.BookmarkRepository, imageRepo domain.ImageRepository, postRepo domain.PostRepository) domain.BookmarkUseCase {
+	return &bookmarkUseCase{
+		bookmarkRepo: bookmarkRepo,
+		imageRepo:    imageRepo,
+		postRepo:     postRepo,
+	}
+}
+
+// AddBookmark
+// @description: 閲覧できる作品をブックマークする。すでにブックマークしている場合はフォルダを移動する
+func (u *bookmarkUseCase) AddBookmark(userID uint, itemType string, itemID uint, folderID *uint) (*domain.Bookmark, error) {
+	if folderID != nil {
+		if _, err := u.ownFolder(userID, *folderID); err != nil {
+			return nil, err
+		}
+	}
+
+	bookmark := &domain.Bookmark{UserID: userID, ItemType: itemType, FolderID: folderID}
+	switch itemType {
+	case domain.BookmarkItemImage:
+		image, err := u.imageRepo.GetByID(itemID)
+		if err != nil {
+			return nil, err
+		}
+		if !image.VisibleTo(userID) {
+			return nil, domain.ErrNotFound
+		}
+		bookmark.ImageID = &image.ID
+	case domain.BookmarkItemPost:
+		post, err := u.postRepo.GetByID(itemID)
+		if err != nil {
+			return nil, err
+		}
+		if !post.VisibleTo(userID) {
+			return nil, domain.ErrNotFound
+		}
+		bookmark.PostID = &post.ID
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+
+	err := u.bookmarkRepo.Save(bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookmark, nil
+}
+
+// RemoveBookmark
+// @description: ブックマークを外す
+func (u *bookmarkUseCase) RemoveBookmark(userID uint, itemType string, itemID uint) error {
+	if itemType != domain.BookmarkItemImage && itemType != domain.BookmarkItemPost {
+		return domain.ErrInvalidInput
+	}
+	return u.bookmarkRepo.Delete(userID, itemType, itemID)
+}
+
+// GetBookmarks
+// @description: ブックマークを新しい順に取得（削除された作品や、公開範囲が変わって閲覧できなくなった作品は除く）
+func (u *bookmarkUseCase) GetBookmarks(userID uint, folderID *uint, page, limit int) ([]*domain.Bookmark, error) {
+	if folderID != nil {
+		if _, err := u.ownFolder(userID, *folderID); err != nil {
+			return nil, err
+		}
+	}
+
+	offset := (page - 1) * limit
+	bookmarks, err := u.bookmarkRepo.GetByUserID(userID, folderID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bookmark := range bookmarks {
+		if bookmark.Post != nil {
+			hideInvisibleImages(bookmark.Post, userID)
+		}
+	}
+	return bookmarks, nil
+}
+
+// GetFolders
+// @description: ブックマークのフォルダを取得
+func (u *bookmarkUseCase) GetFolders(userID uint) ([]*domain.BookmarkFolder, error) {
+	return u.bookmarkRepo.GetFolders(userID)
+}
+
+// CreateFolder
+// @description: ブックマークのフォルダを作成
+func (u *bookmarkUseCase) CreateFolder(userID uint, name string) (*domain.BookmarkFolder, error) {
+	name = strings.TrimSpace(name)
+	if !validFolderName(name) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	folder := &domain.BookmarkFolder{UserID: userID, Name: name}
+	err := u.bookmarkRepo.CreateFolder(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// RenameFolder
+// @description: ブックマークのフォルダの名前を変更
+func (u *bookmarkUseCase) RenameFolder(userID, folderID uint, name string) (*domain.BookmarkFolder, error) {
+	name = strings.TrimSpace(name)
+	if !validFolderName(name) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	folder, err := u.ownFolder(userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	folder.Name = name
+	err = u.bookmarkRepo.UpdateFolder(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// DeleteFolder
+// @description: ブックマークのフォルダを削除（中のブックマークはフォルダなしとして残る）
+func (u *bookmarkUseCase) DeleteFolder(userID, folderID uint) error {
+	if _, err := u.ownFolder(userID, folderID); err != nil {
+		return err
+	}
+	return u.bookmarkRepo.DeleteFolder(folderID)
+}
+
+// ownFolder
+// @description: ユーザーのフォルダを取得（他のユーザーのフォルダは存在しないものとして扱う）
+func (u *bookmarkUseCase) ownFolder(userID, folderID uint) (*domain.BookmarkFolder, error) {
+	folder, err := u.bookmarkRepo.GetFolder(folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if folder.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+
+	return folder, nil
+}
+
+// validFolderName
+// @description: フォルダ名が空でなく長すぎないか
+func validFolderName(name string) bool {
+	return name != "" && utf8.RuneCountInString(name) <= maxBookmarkFolderNameLen
+}
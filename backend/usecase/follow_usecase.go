@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"backend/domain"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// followUseCase
+// @description: フォローユースケースの実装
+type followUseCase struct {
+	followRepo    domain.FollowRepository
+	feedRepo      domain.FeedRepository
+	userRepo      domain.UserRepository
+	notifications domain.NotificationUseCase
+}
+
+// NewFollowUseCase
+// @description: フォローユースケースを初期化
+func NewFollowUseCase(
+	followRepo domain.FollowRepository,
+	feedRepo domain.FeedRepository,
+	userRepo domain.UserRepository,
+	notifications domain.NotificationUseCase,
+) domain.FollowUseCase {
+	return &followUseCase{
+		followRepo:    followRepo,
+		feedRepo:      feedRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+	}
+}
+
+// Follow
+// @description: ユーザーをフォロー（自分自身と退会したユーザーはフォローできない）
+func (u *followUseCase) Follow(userID uint, username string) error {
+	followee, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	if !followee.IsActive {
+		return domain.ErrNotFound
+	}
+	if followee.ID == userID {
+		return domain.ErrInvalidInput
+	}
+
+	err = u.followRepo.Follow(userID, followee.ID)
+	if err != nil {
+		return err
+	}
+
+	u.notifications.Notify(domain.NotificationEvent{
+		Type:        domain.NotificationFollow,
+		RecipientID: followee.ID,
+		ActorID:     userID,
+		TargetType:  domain.NotificationTargetUser,
+		TargetID:    followee.ID,
+	})
+	return nil
+}
+
+// Unfollow
+// @description: フォローを外す
+func (u *followUseCase) Unfollow(userID uint, username string) error {
+	followee, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	return u.followRepo.Unfollow(userID, followee.ID)
+}
+
+// GetFollowers
+// @description: ユーザーのフォロワーを取得
+func (u *followUseCase) GetFollowers(username string, page, limit int) ([]*domain.FollowUser, error) {
+	user, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * limit
+	return u.followRepo.GetFollowers(user.ID, offset, limit)
+}
+
+// GetFollowing
+// @description: ユーザーがフォローしているユーザーを取得
+func (u *followUseCase) GetFollowing(username string, page, limit int) ([]*domain.FollowUser, error) {
+	user, err := u.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * limit
+	return u.followRepo.GetFollowing(user.ID, offset, limit)
+}
+
+// GetFeed
+// @description: フォロー中のユーザーの公開済みの投稿を新しい順に取得。
+// cursorは前のページのNextCursor（空の場合は先頭から）
+func (u *followUseCase) GetFeed(userID uint, cursor string, limit int) (*domain.FeedPage, error) {
+	var before *domain.FeedCursor
+	if cursor != "" {
+		c, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		before = c
+	}
+
+	// 1件多く取得して続きがあるかを判定する
+	posts, err := u.feedRepo.GetFeed(userID, domain.ListedVisibilities(userID), before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.FeedPage{Data: posts}
+	if len(posts) > limit {
+		page.Data = posts[:limit]
+		last := page.Data[limit-1]
+		if last.PublishAt != nil {
+			page.NextCursor = encodeFeedCursor(domain.FeedCursor{PublishAt: *last.PublishAt, ID: last.ID})
+		}
+	}
+
+	for _, post := range page.Data {
+		hideInvisibleImages(post, userID)
+	}
+	return page, nil
+}
+
+// encodeFeedCursor
+// @description: フィードの位置をURLに載せられる文字列にする
+func encodeFeedCursor(c domain.FeedCursor) string {
+	raw := fmt.Sprintf("%d.%d", c.PublishAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor
+// @description: encodeFeedCursorで作った文字列を読み取る
+func decodeFeedCursor(s string) (*domain.FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	publishAt, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, fmt.Errorf("malformed feed cursor")
+	}
+	micros, err := strconv.ParseInt(publishAt, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	postID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.FeedCursor{PublishAt: time.UnixMicro(micros), ID: uint(postID)}, nil
+}
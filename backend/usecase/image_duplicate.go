@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"backend/domain"
+	"fmt"
+	"time"
+)
+
+// nearDuplicateDistance
+// @description: 似ている画像とみなす知覚ハッシュのハミング距離（64ビット中）
+const nearDuplicateDistance = 10
+
+// similarImagesLimit
+// @description: 一度に返す似ている画像の最大数
+const similarImagesLimit = 20
+
+// checkDuplicates
+// @description: アップロードした画像と同一・似ている画像を探し、自分の画像は警告として返し、
+// 他のユーザーの画像は管理者の確認待ちにする
+func (u *imageUseCase) checkDuplicates(image *domain.Image) {
+	matches, err := u.imageRepo.FindSimilar(image, nearDuplicateDistance, 0, similarImagesLimit)
+	if err != nil {
+		// 重複の確認に失敗してもアップロード自体は成功させる
+		fmt.Printf("Failed to check duplicate images: %v\n", err)
+		return
+	}
+
+	for _, match := range matches {
+		if match.Image.UserID == image.UserID {
+			image.Duplicates = append(image.Duplicates, *match)
+			continue
+		}
+
+		flag := &domain.DuplicateFlag{
+			ImageID:        image.ID,
+			MatchedImageID: match.Image.ID,
+			Distance:       match.Distance,
+			Exact:          match.Exact,
+			Status:         domain.DuplicateStatusPending,
+		}
+		if err := u.duplicateRepo.Create(flag); err != nil {
+			fmt.Printf("Failed to flag duplicate image: %v\n", err)
+		}
+	}
+}
+
+// GetSimilarImages
+// @description: 同一または似ている画像を取得（他のユーザーの非公開画像は含めない）
+func (u *imageUseCase) GetSimilarImages(userID, imageID uint) ([]*domain.ImageMatch, error) {
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 閲覧できない画像は存在しないものとして扱う
+	if !image.VisibleTo(userID) {
+		return nil, domain.ErrNotFound
+	}
+
+	return u.imageRepo.FindSimilar(image, nearDuplicateDistance, userID, similarImagesLimit)
+}
+
+// GetDuplicateFlags
+// @description: 状態で重複フラグを取得（未指定の場合は確認待ち）
+func (u *imageUseCase) GetDuplicateFlags(status string, page, limit int) ([]*domain.DuplicateFlag, error) {
+	switch status {
+	case "":
+		status = domain.DuplicateStatusPending
+	case domain.DuplicateStatusPending, domain.DuplicateStatusConfirmed, domain.DuplicateStatusDismissed:
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+
+	offset := (page - 1) * limit
+	return u.duplicateRepo.GetByStatus(status, offset, limit)
+}
+
+// ReviewDuplicateFlag
+// @description: 重複フラグを無断転載（confirmed）または問題なし（dismissed）として確認済みにする
+func (u *imageUseCase) ReviewDuplicateFlag(reviewerID, flagID uint, status string) (*domain.DuplicateFlag, error) {
+	if status != domain.DuplicateStatusConfirmed && status != domain.DuplicateStatusDismissed {
+		return nil, domain.ErrInvalidInput
+	}
+
+	flag, err := u.duplicateRepo.GetByID(flagID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	flag.Status = status
+	flag.ReviewedBy = &reviewerID
+	flag.ReviewedAt = &now
+	if err := u.duplicateRepo.Update(flag); err != nil {
+		return nil, err
+	}
+
+	return flag, nil
+}
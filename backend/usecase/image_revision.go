@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"backend/domain"
+	"io"
+)
+
+// ReplaceImageFile
+// @description: 画像のファイルを新しい版に差し替える（ID・タイトル・閲覧数などはそのまま）。
+// 以前の版はBlobの参照を持ったまま残り、所有者はいつでも戻せる
+func (u *imageUseCase) ReplaceImageFile(userID, imageID uint, r io.Reader, filename string) (*domain.Image, error) {
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ユーザーが画像の所有者かどうかを確認
+	if image.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	file, err := u.storeFile(r, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.imageRepo.AddRevision(image, file)
+	if err != nil {
+		// 版を追加できなかった場合、Blobの参照を戻す
+		u.releaseBlob(file.SHA256)
+		return nil, err
+	}
+
+	u.checkDuplicates(image)
+
+	return image, nil
+}
+
+// GetImageRevisions
+// @description: 画像の版の履歴を新しい順に取得（所有者のみ）
+func (u *imageUseCase) GetImageRevisions(userID, imageID uint) ([]*domain.ImageRevision, error) {
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if image.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	return u.imageRepo.GetRevisions(imageID)
+}
+
+// RevertImage
+// @description: 以前の版を現在のファイルに戻す（版の履歴はそのまま残る）
+func (u *imageUseCase) RevertImage(userID, imageID uint, revision int) (*domain.Image, error) {
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if image.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	if image.Revision == revision {
+		return image, nil
+	}
+
+	rev, err := u.imageRepo.GetRevision(imageID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.imageRepo.SetRevision(image, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
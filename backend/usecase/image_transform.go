@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"backend/domain"
+	"backend/infrastructure/imaging"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// transformSizes
+// @description: 変換後の幅・高さとして指定できる値。任意の値に署名すると、
+// 署名付きURLでもキャッシュに載らない変換をいくらでも作れてしまう
+var transformSizes = map[int]bool{
+	64: true, 128: true, 256: true, 320: true, 480: true, 640: true,
+	800: true, 1024: true, 1280: true, 1600: true, 1920: true, 2560: true,
+}
+
+// maxOriginalBytes
+// @description: 変換元としてダウンロードする画像サイズの上限
+const maxOriginalBytes = 100 * 1024 * 1024
+
+// transformCacheVersion
+// @description: 変換結果のキャッシュの版（同じパラメータでも結果が変わる変更をしたら上げる）
+const transformCacheVersion = 2
+
+// restrictedTransformTTL
+// @description: ログインなしでは見られない画像の変換URLの有効期間
+const restrictedTransformTTL = time.Hour
+
+// GetTransformURL
+// @description: 署名付きの変換URLを発行（画像を閲覧できる場合のみ）。
+// /img/:id はログインなしで使えるので、メンバー限定・非公開の画像のURLには期限を付ける
+func (u *imageUseCase) GetTransformURL(userID, imageID uint, opts domain.ImageTransformOptions) (string, error) {
+	opts, err := normalizeTransformOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return "", err
+	}
+	if !image.VisibleTo(userID) {
+		return "", domain.ErrNotFound
+	}
+
+	query := url.Values{}
+	query.Set("w", strconv.Itoa(opts.Width))
+	query.Set("h", strconv.Itoa(opts.Height))
+	query.Set("fit", opts.Fit)
+	query.Set("fmt", opts.Format)
+	// 版を指定しておくと、ファイルを差し替えても発行済みのURLは同じ画像を返す
+	opts.Revision = image.Revision
+	query.Set("v", strconv.Itoa(opts.Revision))
+	if !image.VisibleTo(0) {
+		opts.Expires = time.Now().Add(restrictedTransformTTL).Unix()
+		query.Set("exp", strconv.FormatInt(opts.Expires, 10))
+	}
+	query.Set("sig", signTransform(imageID, opts, image.Visibility))
+
+	return fmt.Sprintf("/img/%d?%s", imageID, query.Encode()), nil
+}
+
+// TransformImage
+// @description: 署名を検証して画像を変換（結果はディスクにキャッシュ）
+func (u *imageUseCase) TransformImage(ctx context.Context, imageID uint, opts domain.ImageTransformOptions, signature string) (*domain.TransformedImage, error) {
+	opts, err := normalizeTransformOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt time.Time
+	if opts.Expires != 0 {
+		expiresAt = time.Unix(opts.Expires, 0)
+		if !time.Now().Before(expiresAt) {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	image, err := u.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+	// 期限付きのURLは発行時の公開範囲にも署名しているので、公開範囲を狭めると使えなくなる
+	if !hmac.Equal([]byte(signature), []byte(signTransform(imageID, opts, image.Visibility))) {
+		return nil, domain.ErrForbidden
+	}
+	// 公開中に発行した無期限のURLは、ログインなしで見られなくなった後は配信しない
+	if opts.Expires == 0 && !image.VisibleTo(0) {
+		return nil, domain.ErrNotFound
+	}
+
+	// 版の指定がなければ現在のファイルを変換する
+	sourceURL := image.URL
+	if opts.Revision != 0 && opts.Revision != image.Revision {
+		rev, err := u.imageRepo.GetRevision(imageID, opts.Revision)
+		if err != nil {
+			return nil, err
+		}
+		sourceURL = rev.URL
+	}
+
+	// 元画像のURLが変われば別のキャッシュになる
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%d|%d|%s|%s", transformCacheVersion, sourceURL, opts.Width, opts.Height, opts.Fit, opts.Format)))
+	key := hex.EncodeToString(sum[:])
+
+	result := &domain.TransformedImage{
+		ContentType: imaging.ContentType(opts.Format),
+		ETag:        key,
+		ExpiresAt:   expiresAt,
+	}
+
+	if data, ok := u.transformCache.Get(key); ok {
+		result.Data = data
+		return result, nil
+	}
+
+	// 同じ変換への同時のリクエストは1回の変換の結果を共有する
+	ch := u.transformGroup.DoChan(key, func() (interface{}, error) {
+		return u.renderTransform(ctx, key, sourceURL, opts)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		result.Data = res.Val.([]byte)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return result, nil
+}
+
+// renderTransform
+// @description: 元画像をダウンロードして変換し、キャッシュに保存する（同時に変換する数は制限する）
+func (u *imageUseCase) renderTransform(ctx context.Context, key, sourceURL string, opts domain.ImageTransformOptions) ([]byte, error) {
+	select {
+	case u.transformSlots <- struct{}{}:
+		defer func() { <-u.transformSlots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// 順番を待っている間に他のリクエストが同じ変換を終えていることがある
+	if data, ok := u.transformCache.Get(key); ok {
+		return data, nil
+	}
+
+	body, err := u.cloudinarySvc.DownloadImage(ctx, sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	src, _, err := imaging.Decode(io.LimitReader(body, maxOriginalBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode original image: %w", err)
+	}
+
+	var buf bytes.Buffer
+	transformed := imaging.Transform(src, opts.Width, opts.Height, opts.Fit)
+	if err := imaging.Encode(&buf, transformed, opts.Format); err != nil {
+		return nil, fmt.Errorf("failed to encode transformed image: %w", err)
+	}
+
+	if err := u.transformCache.Put(key, buf.Bytes()); err != nil {
+		// キャッシュに失敗しても変換結果は返す
+		fmt.Printf("Failed to cache transformed image: %v\n", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// normalizeTransformOptions
+// @description: 変換パラメータを検証してデフォルト値を補う
+func normalizeTransformOptions(opts domain.ImageTransformOptions) (domain.ImageTransformOptions, error) {
+	if opts.Revision < 0 || opts.Expires < 0 {
+		return opts, domain.ErrInvalidInput
+	}
+	if opts.Width == 0 && opts.Height == 0 {
+		return opts, domain.ErrInvalidInput
+	}
+	if (opts.Width != 0 && !transformSizes[opts.Width]) || (opts.Height != 0 && !transformSizes[opts.Height]) {
+		return opts, domain.ErrInvalidInput
+	}
+
+	switch opts.Fit {
+	case "":
+		opts.Fit = "contain"
+	case "contain", "cover", "fill":
+	default:
+		return opts, domain.ErrInvalidInput
+	}
+
+	// WebPは可逆圧縮でしか書き出せず写真では大きくなるので、指定がなければJPEGにする
+	switch opts.Format {
+	case "", "jpg":
+		opts.Format = "jpeg"
+	case "jpeg", "png", "webp":
+	default:
+		return opts, domain.ErrInvalidInput
+	}
+
+	return opts, nil
+}
+
+// signTransform
+// @description: 画像IDと変換パラメータのHMAC署名を生成（版を指定しない発行済みのURLも有効なままにする）。
+// 期限付きのURLは期限と発行時の公開範囲にも署名する
+func signTransform(imageID uint, opts domain.ImageTransformOptions, visibility string) string {
+	mac := hmac.New(sha256.New, urlSigningSecret())
+	fmt.Fprintf(mac, "%d|%d|%d|%s|%s", imageID, opts.Width, opts.Height, opts.Fit, opts.Format)
+	if opts.Revision != 0 {
+		fmt.Fprintf(mac, "|%d", opts.Revision)
+	}
+	if opts.Expires != 0 {
+		fmt.Fprintf(mac, "|%d|%s", opts.Expires, visibility)
+	}
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// urlSigningSecret
+// @description: ログインなしで使えるURL（変換URL・共有リンク）の署名に使う鍵
+func urlSigningSecret() []byte {
+	secret := os.Getenv("IMAGE_URL_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	if secret == "" {
+		secret = "default-secret-key" // 本番環境では強力なシークレットキーを使用する
+	}
+	return []byte(secret)
+}
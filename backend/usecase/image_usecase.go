@@ -6,91 +6,533 @@
 package usecase
 
 import (
-	"slices"
 	"backend/domain"
 	"backend/infrastructure/cloudinary"
+	"backend/infrastructure/imaging"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"image"
+	"io"
 	"mime/multipart"
-	"path/filepath"
+	"os"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // imageUseCase
 // @description: 画像ユースケースの実装
 type imageUseCase struct {
-	imageRepo     domain.ImageRepository
-	cloudinarySvc *cloudinary.Service
+	imageRepo      domain.ImageRepository
+	blobRepo       domain.BlobRepository
+	duplicateRepo  domain.DuplicateFlagRepository
+	outboxRepo     domain.OutboxRepository
+	bookmarkRepo   domain.BookmarkRepository
+	cloudinarySvc  *cloudinary.Service
+	variantGen     *imaging.Generator
+	transformCache *imaging.DiskCache
+	transformGroup singleflight.Group // キャッシュキーごとの実行中の変換
+	transformSlots chan struct{}      // 同時に実行できる変換の枠
+	keepMetadata   map[string]bool    // 画像に残すEXIFの項目
 }
 
 // NewImageUseCase
 // @description: 画像ユースケースを初期化
-func NewImageUseCase(imageRepo domain.ImageRepository) domain.ImageUseCase {
+func NewImageUseCase(
+	imageRepo domain.ImageRepository,
+	blobRepo domain.BlobRepository,
+	duplicateRepo domain.DuplicateFlagRepository,
+	outboxRepo domain.OutboxRepository,
+	bookmarkRepo domain.BookmarkRepository,
+) domain.ImageUseCase {
 	cloudinarySvc, err := cloudinary.NewService()
 	if err != nil {
 		panic(fmt.Sprintf("Failed to initialize Cloudinary service: %v", err))
 	}
 
+	variantGen, err := imaging.NewGenerator()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize image variant generator: %v", err))
+	}
+
+	transformCache, err := imaging.NewDiskCacheFromEnv()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize image transform cache: %v", err))
+	}
+
+	keepMetadata, err := metadataWhitelistFromEnv()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize image metadata whitelist: %v", err))
+	}
+
 	return &imageUseCase{
-		imageRepo:     imageRepo,
-		cloudinarySvc: cloudinarySvc,
+		imageRepo:      imageRepo,
+		blobRepo:       blobRepo,
+		duplicateRepo:  duplicateRepo,
+		outboxRepo:     outboxRepo,
+		bookmarkRepo:   bookmarkRepo,
+		cloudinarySvc:  cloudinarySvc,
+		variantGen:     variantGen,
+		transformCache: transformCache,
+		transformSlots: make(chan struct{}, runtime.NumCPU()),
+		keepMetadata:   keepMetadata,
 	}
 }
 
-// UploadImage
-// @description: 画像をアップロード
-func (u *imageUseCase) UploadImage(userID uint, title, description, tags string, imageData []byte, filename string) (*domain.Image, error) {
-	// Validate file type
-	if !isValidImageType(filename) {
-		return nil, domain.ErrInvalidFileType
+// metadataWhitelistFromEnv
+// @description: IMAGE_METADATA_WHITELIST（software, created_atのカンマ区切り）から残すEXIFの項目を読み取る。
+// 未設定の場合は何も残さない
+func metadataWhitelistFromEnv() (map[string]bool, error) {
+	keep := map[string]bool{}
+	for _, field := range strings.Split(os.Getenv("IMAGE_METADATA_WHITELIST"), ",") {
+		field = strings.TrimSpace(field)
+		switch field {
+		case "":
+		case "software", "created_at":
+			keep[field] = true
+		default:
+			return nil, fmt.Errorf("unknown metadata field: %q", field)
+		}
 	}
+	return keep, nil
+}
+
+// Cloudinaryのフォルダ
+const (
+	incomingFolder = "images/incoming" // ハッシュが分かるまでの一時的な保存先
+	blobFolder     = "images/blobs"    // Blobの元画像（内容のハッシュに作成ごとの乱数を付けたPublic ID）
+	variantFolder  = "images/variants" // 縮小版
+)
 
-	// 画像サイズは100MBまで
-	if len(imageData) > 100*1024*1024 {
-		return nil, domain.ErrFileTooLarge
+// defaultMaxUploadBytes
+// @description: アップロードできるファイルサイズの上限（MAX_UPLOAD_BYTESで上書き可能）
+const defaultMaxUploadBytes = 100 * 1024 * 1024
+
+// inspectBufferSize
+// @description: 形式の検証のために最初に読み込むバイト数
+const inspectBufferSize = 1024 * 1024
+
+// maxInspectBytes
+// @description: 形式とサイズが分かるまで読み足す上限
+const maxInspectBytes = 32 * 1024 * 1024
+
+// MaxUploadBytes
+// @description: アップロードできるファイルサイズの上限を取得
+func MaxUploadBytes() int64 {
+	if env := os.Getenv("MAX_UPLOAD_BYTES"); env != "" {
+		if n, err := strconv.ParseInt(env, 10, 64); err == nil && n > 0 {
+			return n
+		}
 	}
+	return defaultMaxUploadBytes
+}
 
-	// Cloudinaryにアップロード
-	ctx := context.Background()
-	result, err := u.cloudinarySvc.UploadImage(ctx, strings.NewReader(string(imageData)), filename, "images")
+// uploadReader
+// @description: 読み込んだバイト数を数え、上限を超えたらErrFileTooLargeを返す
+type uploadReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+	err   error // 読み込み元で発生したエラー（EOF以外）
+}
+
+// Read
+// @description: io.Readerを実装
+func (r *uploadReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.n > r.limit {
+		err = domain.ErrFileTooLarge
+	}
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// storedOriginal
+// @description: ストリーミングで保存した元画像
+type storedOriginal struct {
+	publicID  string
+	secureURL string
+	sha256    string
+	size      int64
+	decoded   image.Image
+}
+
+// UploadImage
+// @description: 画像をアップロード（全体をメモリに載せずに検証・ハッシュ計算・Cloudinary転送を行う）。
+// 位置情報や端末情報を含むメタデータは保存前に取り除く
+func (u *imageUseCase) UploadImage(userID uint, title, description, tags string, r io.Reader, filename string) (*domain.Image, error) {
+	file, err := u.storeFile(r, filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload to Cloudinary: %w", err)
+		return nil, err
 	}
 
 	// 画像レコードを作成
 	image := &domain.Image{
-		UserID:       userID,
-		Title:        title,
-		Description:  description,
-		Tags:         tags,
-		CloudinaryID: result.PublicID,
-		URL:          result.SecureURL,
-		Width:        result.Width,
-		Height:       result.Height,
-		FileSize:     int64(result.Bytes),
-		Format:       result.Format,
-		IsPublic:     true,
-		ViewCount:    0,
+		UserID:      userID,
+		Title:       title,
+		Description: description,
+		Tags:        tags,
+		Visibility:  domain.VisibilityPublic,
+		ViewCount:   0,
 	}
+	image.ApplyRevision(file)
 
 	err = u.imageRepo.Create(image)
 	if err != nil {
-		// データベース保存に失敗した場合、Cloudinaryから削除
-		u.cloudinarySvc.DeleteImage(ctx, result.PublicID)
+		// データベース保存に失敗した場合、Blobの参照を戻す
+		u.releaseBlob(file.SHA256)
 		return nil, err
 	}
 
+	u.checkDuplicates(image)
+
 	return image, nil
 }
 
+// storeFile
+// @description: アップロードされたファイルを検証してBlobに保存し、画像の版として返す（版の番号は未設定）
+func (u *imageUseCase) storeFile(r io.Reader, filename string) (*domain.ImageRevision, error) {
+	src := &uploadReader{r: r, limit: MaxUploadBytes()}
+
+	// 先頭部分だけで形式とサイズを検証（この時点ではCloudinaryに何も送らない）
+	header, info, err := readHeader(src, filename)
+	if err != nil {
+		return nil, err
+	}
+	// 以降は読み込んだ先頭部分と残りをつなげて読む
+	body := io.MultiReader(bytes.NewReader(header), src)
+
+	// EXIFは取り除く前に読んでおく
+	meta := imaging.ReadMetadata(header, info.Format)
+	width, height := info.Width, info.Height
+
+	// ハッシュは保存し終えるまで分からないので、まず一時的な名前で保存する
+	tempID, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var original *storedOriginal
+	if meta.Orientation > 1 && (info.Format == "jpeg" || info.Format == "png" || info.Format == "webp") {
+		// 向きを直すには画素を並べ替える必要があるため、デコードして書き出し直す
+		// （向きのタグはメタデータと一緒に取り除かれるので、直さずに保存すると回転したまま表示される）
+		width, height = imaging.OrientedSize(width, height, meta.Orientation)
+		original, err = u.storeReoriented(ctx, body, src, info.Format, meta.Orientation, tempID)
+	} else {
+		// Cloudinaryへの転送・ハッシュ計算・デコードを1回の読み込みで行う
+		stripped := imaging.StripMetadata(body, info.Format)
+		original, err = u.streamOriginal(ctx, stripped, src, tempID, nil)
+		stripped.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 内容のハッシュをキーにしたBlobに保存（同じ内容のBlobがあればそれを共有する）
+	blob, err := u.storeBlob(ctx, original, &domain.Blob{
+		SHA256:   original.sha256,
+		Width:    width,
+		Height:   height,
+		FileSize: original.size,
+		Format:   info.Format,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 重複検出用の知覚ハッシュ
+	phash := int64(imaging.DHash(original.decoded))
+
+	return &domain.ImageRevision{
+		CloudinaryID: blob.CloudinaryID,
+		URL:          blob.URL,
+		Width:        blob.Width,
+		Height:       blob.Height,
+		FileSize:     blob.FileSize,
+		Format:       blob.Format,
+		SHA256:       blob.SHA256,
+		PHash:        &phash,
+		Variants:     blob.Variants,
+		Metadata:     u.keptMetadata(meta),
+	}, nil
+}
+
+// storeBlob
+// @description: 一時的な名前で保存した元画像をBlobにする。同じ内容のBlobがあれば参照を増やして一時ファイルを捨て、
+// なければ内容のハッシュの名前に変更して縮小版を生成する
+func (u *imageUseCase) storeBlob(ctx context.Context, original *storedOriginal, blob *domain.Blob) (*domain.Blob, error) {
+	existing, err := u.blobRepo.Acquire(blob.SHA256)
+	if err == nil {
+		u.discardFiles(original.publicID)
+		return existing, nil
+	}
+	if err != domain.ErrNotFound {
+		u.discardFiles(original.publicID)
+		return nil, err
+	}
+
+	// 作成ごとに別のPublic IDにする。同じ内容が削除された直後に再びアップロードされても、
+	// 記録済みの古いファイルの削除が新しいファイルを消すことはない
+	nonce, err := newUploadID()
+	if err != nil {
+		u.discardFiles(original.publicID)
+		return nil, err
+	}
+	baseName := blob.SHA256 + "-" + nonce[:16]
+	blob.CloudinaryID = blobFolder + "/" + baseName
+	blob.URL, err = u.cloudinarySvc.RenameImage(ctx, original.publicID, blob.CloudinaryID)
+	if err != nil {
+		u.discardFiles(original.publicID)
+		return nil, err
+	}
+
+	// 縮小版を生成してアップロード（失敗しても元画像だけで保存し、縮小版はワーカーに作り直させる）
+	blob.Variants, err = u.uploadVariants(ctx, original.decoded, baseName)
+	regenerate := err != nil
+	if regenerate {
+		fmt.Printf("Failed to create variants of %s, queueing regeneration: %v\n", blob.CloudinaryID, err)
+		blob.Variants = domain.ImageVariants{}
+	}
+
+	publicID, variants := blob.CloudinaryID, blob.Variants
+	stored, err := u.blobRepo.CreateOrAcquire(blob)
+	if err != nil {
+		u.discardFiles(publicID)
+		u.discardVariants(variants)
+		return nil, err
+	}
+	if stored.CloudinaryID != publicID {
+		// 同じ内容を同時にアップロードしていた側が先にBlobを作ったので、こちらのファイルは使わない
+		u.discardFiles(publicID)
+		u.discardVariants(variants)
+	} else if regenerate {
+		task := &domain.OutboxTask{Action: domain.OutboxActionGenerateVariants, PublicID: publicID}
+		if err := u.outboxRepo.Enqueue(task); err != nil {
+			// 縮小版がなくても元画像で表示できる
+			fmt.Printf("Failed to queue variant regeneration of %s: %v\n", publicID, err)
+		}
+	}
+
+	return stored, nil
+}
+
+// releaseBlob
+// @description: Blobの参照を戻す（最後の参照だった場合はファイルの削除が記録される）
+func (u *imageUseCase) releaseBlob(sha string) {
+	if _, err := u.blobRepo.Release(sha); err != nil {
+		fmt.Printf("Failed to release image blob: %v\n", err)
+	}
+}
+
+// discardFiles
+// @description: 不要になったファイルの削除を記録し、ワーカーに再試行しながら削除させる
+func (u *imageUseCase) discardFiles(publicIDs ...string) {
+	tasks := make([]*domain.OutboxTask, 0, len(publicIDs))
+	for _, publicID := range publicIDs {
+		tasks = append(tasks, &domain.OutboxTask{Action: domain.OutboxActionDeleteImage, PublicID: publicID})
+	}
+	if err := u.outboxRepo.Enqueue(tasks...); err != nil {
+		// 記録できなかったファイルはストレージの整合性チェックで見つかる
+		fmt.Printf("Failed to queue deletion of %v: %v\n", publicIDs, err)
+	}
+}
+
+// discardVariants
+// @description: 縮小版の削除を記録
+func (u *imageUseCase) discardVariants(variants domain.ImageVariants) {
+	publicIDs := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		publicIDs = append(publicIDs, variant.CloudinaryID)
+	}
+	u.discardFiles(publicIDs...)
+}
+
+// storeReoriented
+// @description: 画像をデコードして向きを直し、書き出し直したものを保存する（メタデータは書き出さない）
+func (u *imageUseCase) storeReoriented(ctx context.Context, r io.Reader, src *uploadReader, format string, orientation int, publicID string) (*storedOriginal, error) {
+	decoded, _, err := imaging.Decode(r)
+	if src.err != nil {
+		return nil, src.err
+	}
+	if err != nil {
+		return nil, domain.ErrInvalidFileType
+	}
+	decoded = imaging.ApplyOrientation(decoded, orientation)
+
+	encodedR, encodedW := io.Pipe()
+	defer encodedR.Close()
+	go func() {
+		encodedW.CloseWithError(imaging.EncodeOriginal(encodedW, decoded, format))
+	}()
+
+	return u.streamOriginal(ctx, encodedR, src, publicID, decoded)
+}
+
+// streamOriginal
+// @description: 読み込みながらCloudinaryへの転送・SHA-256の計算・デコードを並行して行う。
+// decodedが渡された場合はデコードを省略する
+func (u *imageUseCase) streamOriginal(ctx context.Context, r io.Reader, src *uploadReader, publicID string, decoded image.Image) (*storedOriginal, error) {
+	storageR, storageW := io.Pipe()
+	original := &storedOriginal{decoded: decoded}
+	hasher := sha256.New()
+	writers := []io.Writer{hasher, storageW}
+
+	var wg sync.WaitGroup
+	var uploadErr, decodeErr error
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		result, err := u.cloudinarySvc.UploadImage(ctx, storageR, publicID, incomingFolder)
+		if err != nil {
+			uploadErr = err
+			storageR.CloseWithError(err)
+			return
+		}
+		original.publicID = result.PublicID
+		original.secureURL = result.SecureURL
+		storageR.Close()
+	}()
+
+	var decodeW *io.PipeWriter
+	if decoded == nil {
+		var decodeR *io.PipeReader
+		decodeR, decodeW = io.Pipe()
+		writers = append(writers, decodeW)
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			original.decoded, _, decodeErr = imaging.Decode(decodeR)
+			if decodeErr != nil {
+				// 書き込み側を止めてCloudinaryへの転送も中断させる
+				decodeR.CloseWithError(domain.ErrInvalidFileType)
+				return
+			}
+			// デコーダが読まなかった残りを読み捨てる
+			io.Copy(io.Discard, decodeR)
+		}()
+	}
+
+	size, copyErr := io.Copy(io.MultiWriter(writers...), r)
+	storageW.CloseWithError(copyErr)
+	if decodeW != nil {
+		decodeW.CloseWithError(copyErr)
+	}
+	wg.Wait()
+
+	var err error
+	switch {
+	case src.err != nil:
+		err = src.err
+	case errors.Is(copyErr, domain.ErrInvalidFileType), errors.Is(copyErr, imaging.ErrMalformed),
+		errors.Is(copyErr, io.ErrUnexpectedEOF), decodeErr != nil && copyErr == nil:
+		// デコードの失敗が転送失敗の結果である場合は除く
+		err = domain.ErrInvalidFileType
+	case uploadErr != nil:
+		err = fmt.Errorf("failed to upload to Cloudinary: %w", uploadErr)
+	case copyErr != nil:
+		err = fmt.Errorf("failed to stream upload: %w", copyErr)
+	}
+	if err != nil {
+		if original.publicID != "" {
+			u.discardFiles(original.publicID)
+		}
+		return nil, err
+	}
+
+	original.sha256 = hex.EncodeToString(hasher.Sum(nil))
+	original.size = size
+	return original, nil
+}
+
+// keptMetadata
+// @description: 読み取ったEXIFのうち許可された項目だけを残す
+func (u *imageUseCase) keptMetadata(meta *imaging.Metadata) domain.ImageMetadata {
+	var kept domain.ImageMetadata
+	if u.keepMetadata["software"] {
+		kept.Software = meta.Software
+	}
+	if u.keepMetadata["created_at"] {
+		kept.CreatedAt = meta.CreatedAt
+	}
+	return kept
+}
+
+// uploadVariants
+// @description: 縮小版を生成してCloudinaryにアップロード
+func (u *imageUseCase) uploadVariants(ctx context.Context, src image.Image, baseName string) (domain.ImageVariants, error) {
+	variants, err := uploadVariants(ctx, u.cloudinarySvc, u.variantGen, src, baseName)
+	if err != nil {
+		// アップロード済みの縮小版を削除
+		u.discardVariants(variants)
+		return nil, err
+	}
+	return variants, nil
+}
+
+// uploadVariants
+// @description: 縮小版を生成してbaseNameを元にしたPublic IDでアップロードする。
+// 失敗した場合もそれまでにアップロードした縮小版を返す
+func uploadVariants(ctx context.Context, cloudinarySvc *cloudinary.Service, variantGen *imaging.Generator, src image.Image, baseName string) (domain.ImageVariants, error) {
+	derivatives, err := variantGen.Generate(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate variants: %w", err)
+	}
+
+	variants := domain.ImageVariants{}
+	for _, d := range derivatives {
+		if d.SameAs != "" {
+			// 縮小にならないサイズは同じファイルを指す（SameAsの派生画像が先に並ぶ）
+			variants[d.Name] = variants[d.SameAs]
+			continue
+		}
+
+		result, err := cloudinarySvc.UploadImage(ctx, bytes.NewReader(d.Data), baseName+"_"+d.Name, variantFolder)
+		if err != nil {
+			return variants, fmt.Errorf("failed to upload %s variant to Cloudinary: %w", d.Name, err)
+		}
+
+		variants[d.Name] = domain.ImageVariant{
+			CloudinaryID: result.PublicID,
+			URL:          result.SecureURL,
+			Width:        d.Width,
+			Height:       d.Height,
+			Format:       d.Format,
+		}
+	}
+
+	return variants, nil
+}
+
 // GetImage
-// @description: 画像をIDで取得
-func (u *imageUseCase) GetImage(imageID uint) (*domain.Image, error) {
+// @description: 画像をIDで取得（閲覧できない画像は存在しないものとして扱う）
+func (u *imageUseCase) GetImage(viewerID, imageID uint) (*domain.Image, error) {
 	image, err := u.imageRepo.GetByID(imageID)
 	if err != nil {
 		return nil, err
 	}
 
+	if !image.VisibleTo(viewerID) {
+		return nil, domain.ErrNotFound
+	}
+	if image.UserID == viewerID {
+		if err := u.fillBookmarkCounts([]*domain.Image{image}); err != nil {
+			return nil, err
+		}
+	}
+
 	// 閲覧数を増やす
 	go u.imageRepo.IncrementViewCount(imageID)
 
@@ -101,14 +543,43 @@ func (u *imageUseCase) GetImage(imageID uint) (*domain.Image, error) {
 // @description: ユーザーIDで画像を取得
 func (u *imageUseCase) GetUserImages(userID uint, page, limit int) ([]*domain.Image, error) {
 	offset := (page - 1) * limit
-	return u.imageRepo.GetByUserID(userID, offset, limit)
+	images, err := u.imageRepo.GetByUserID(userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.fillBookmarkCounts(images); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// fillBookmarkCounts
+// @description: 所有者向けにブックマーク数を設定する（誰がブックマークしたかは返さない）
+func (u *imageUseCase) fillBookmarkCounts(images []*domain.Image) error {
+	ids := make([]uint, len(images))
+	for i, image := range images {
+		ids[i] = image.ID
+	}
+
+	counts, err := u.bookmarkRepo.CountByItems(domain.BookmarkItemImage, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		count := counts[image.ID]
+		image.BookmarkCount = &count
+	}
+	return nil
 }
 
 // GetPublicImages
-// @description: 公開画像を取得
-func (u *imageUseCase) GetPublicImages(page, limit int) ([]*domain.Image, error) {
+// @description: 閲覧者の一覧に出る画像を取得（ログイン中はメンバー限定の画像も含む）
+func (u *imageUseCase) GetPublicImages(viewerID uint, page, limit int) ([]*domain.Image, error) {
 	offset := (page - 1) * limit
-	return u.imageRepo.GetPublic(offset, limit)
+	return u.imageRepo.GetPublic(viewerID, offset, limit)
 }
 
 // UpdateImage
@@ -118,9 +589,13 @@ func (u *imageUseCase) UpdateImage(
 	imageID uint,
 	title,
 	description,
-	tags string,
-	isPublic bool,
+	tags,
+	visibility string,
 ) (*domain.Image, error) {
+	if visibility != "" && !domain.ValidVisibility(visibility) {
+		return nil, domain.ErrInvalidInput
+	}
+
 	image, err := u.imageRepo.GetByID(imageID)
 	if err != nil {
 		return nil, err
@@ -134,7 +609,9 @@ func (u *imageUseCase) UpdateImage(
 	image.Title = title
 	image.Description = description
 	image.Tags = tags
-	image.IsPublic = isPublic
+	if visibility != "" {
+		image.Visibility = visibility
+	}
 
 	err = u.imageRepo.Update(image)
 	if err != nil {
@@ -157,23 +634,21 @@ func (u *imageUseCase) DeleteImage(userID, imageID uint) error {
 		return domain.ErrForbidden
 	}
 
-	// Cloudinaryから削除
-	ctx := context.Background()
-	err = u.cloudinarySvc.DeleteImage(ctx, image.CloudinaryID)
-	if err != nil {
-		// ログを出力してデータベース削除を続行
-		fmt.Printf("Failed to delete image from Cloudinary: %v\n", err)
-	}
-
-	// データベースから削除
+	// ゴミ箱に移す（ファイルは保持期間が過ぎて完全に削除されるまで残す）
 	return u.imageRepo.Delete(imageID)
 }
 
 // SearchImages
-// @description: 画像をクエリで検索
-func (u *imageUseCase) SearchImages(query string, page, limit int) ([]*domain.Image, error) {
+// @description: 画像を検索条件で検索
+func (u *imageUseCase) SearchImages(filter domain.SearchFilter, page, limit int) ([]*domain.Image, error) {
 	offset := (page - 1) * limit
-	return u.imageRepo.Search(query, offset, limit)
+	return u.imageRepo.Search(filter, offset, limit)
+}
+
+// GetImageFacets
+// @description: 検索条件に一致する画像のタグ・投稿者・月別件数を取得
+func (u *imageUseCase) GetImageFacets(filter domain.SearchFilter) (*domain.SearchFacets, error) {
+	return u.imageRepo.Facets(filter, facetLimit)
 }
 
 // GetImagesByTags
@@ -189,13 +664,32 @@ func (u *imageUseCase) IncrementViewCount(imageID uint) error {
 	return u.imageRepo.IncrementViewCount(imageID)
 }
 
-// isValidImageType
-// @description: ファイルタイプが有効かどうかを確認
-func isValidImageType(filename string) bool {
-	ext := strings.ToLower(filepath.Ext(filename))
-	validExts := []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".bmp"}
-
-	return slices.Contains(validExts, ext)
+// readHeader
+// @description: 形式とサイズが分かるまで先頭部分を読み込んで画像を検証し、ドメインのエラーに変換する
+// （最初はinspectBufferSizeだけ読み、足りなければmaxInspectBytesまで倍にして読み足す）
+func readHeader(src *uploadReader, filename string) ([]byte, *imaging.Info, error) {
+	var header []byte
+	for size := inspectBufferSize; ; size = min(size*2, maxInspectBytes) {
+		chunk := make([]byte, size-len(header))
+		n, readErr := io.ReadFull(src, chunk)
+		header = append(header, chunk[:n]...)
+		if src.err != nil {
+			return nil, nil, src.err
+		}
+
+		info, err := imaging.Inspect(bytes.NewReader(header), filename)
+		// ICCプロファイルやXMPが大きいと、画像のサイズが読み込んだ範囲より後ろにある
+		if errors.Is(err, imaging.ErrTruncated) && readErr == nil && size < maxInspectBytes {
+			continue
+		}
+		switch {
+		case errors.Is(err, imaging.ErrTooManyPixels):
+			return nil, nil, domain.ErrImageTooLarge
+		case err != nil:
+			return nil, nil, domain.ErrInvalidFileType
+		}
+		return header, info, nil
+	}
 }
 
 // UploadImageFromFile
@@ -208,12 +702,5 @@ func (u *imageUseCase) UploadImageFromFile(userID uint, title, description, tags
 	}
 	defer src.Close()
 
-	// ファイルデータを読み込む
-	fileData := make([]byte, file.Size)
-	_, err = src.Read(fileData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	return u.UploadImage(userID, title, description, tags, fileData, file.Filename)
+	return u.UploadImage(userID, title, description, tags, src, file.Filename)
 }
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"backend/domain"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// importUseCase
+// @description: 取り込みユースケースの実装
+type importUseCase struct {
+	importRepo   domain.ImportRepository
+	userRepo     domain.UserRepository
+	imageUseCase domain.ImageUseCase
+}
+
+// NewImportUseCase
+// @description: 取り込みユースケースを初期化
+func NewImportUseCase(
+	importRepo domain.ImportRepository,
+	userRepo domain.UserRepository,
+	imageUseCase domain.ImageUseCase,
+) domain.ImportUseCase {
+	return &importUseCase{
+		importRepo:   importRepo,
+		userRepo:     userRepo,
+		imageUseCase: imageUseCase,
+	}
+}
+
+// IsImported
+// @description: 取り込み済みかどうか
+func (u *importUseCase) IsImported(source, externalID string) (bool, error) {
+	return u.importRepo.Exists(source, externalID)
+}
+
+// ImportPost
+// @description: 画像をアップロードし、元の投稿日時で公開済みの投稿を作成する。
+// 過去の投稿なので、ストリーム・Webhook・通知には流さない
+func (u *importUseCase) ImportPost(req domain.ImportPost) (*domain.Post, error) {
+	if req.Source == "" || req.ExternalID == "" || strings.TrimSpace(req.Title) == "" || len(req.Files) == 0 || req.PostedAt.IsZero() {
+		return nil, domain.ErrInvalidInput
+	}
+	if req.Visibility == "" {
+		req.Visibility = domain.VisibilityPublic
+	}
+	if !domain.ValidVisibility(req.Visibility) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	imported, err := u.importRepo.Exists(req.Source, req.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if imported {
+		return nil, domain.ErrConflict
+	}
+
+	user, err := u.importer(req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	imageIDs := make([]uint, 0, len(req.Files))
+	for _, path := range req.Files {
+		image, err := u.uploadFile(user.ID, req.Title, req.Description, req.Tags, req.Visibility, path)
+		if err != nil {
+			u.discardImages(user.ID, imageIDs)
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		imageIDs = append(imageIDs, image.ID)
+	}
+
+	postedAt := req.PostedAt
+	post := &domain.Post{
+		UserID:      user.ID,
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Visibility:  req.Visibility,
+		Status:      domain.PostStatusPublished,
+		PublishAt:   &postedAt,
+		AnnouncedAt: &postedAt, // 過去の投稿を新着として知らせない
+		CreatedAt:   postedAt,
+		UpdatedAt:   postedAt,
+	}
+	item := &domain.ImportedItem{
+		Source:     req.Source,
+		ExternalID: req.ExternalID,
+		UserID:     user.ID,
+	}
+	if err := u.importRepo.Save(item, post, imageIDs); err != nil {
+		u.discardImages(user.ID, imageIDs)
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// ImportImage
+// @description: 画像を1枚アップロードし、元の作成日時があれば書き換える
+func (u *importUseCase) ImportImage(req domain.ImportImage) (*domain.Image, error) {
+	if strings.TrimSpace(req.Title) == "" || req.File == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if req.Visibility == "" {
+		req.Visibility = domain.VisibilityPublic
+	}
+	if !domain.ValidVisibility(req.Visibility) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	user, err := u.importer(req.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := u.uploadFile(user.ID, req.Title, req.Description, req.Tags, req.Visibility, req.File)
+	if err != nil {
+		return nil, err
+	}
+	if req.CreatedAt.IsZero() {
+		return image, nil
+	}
+
+	if err := u.importRepo.BackdateImage(image.ID, req.CreatedAt); err != nil {
+		u.discardImages(user.ID, []uint{image.ID})
+		return nil, err
+	}
+	image.CreatedAt = req.CreatedAt
+	image.UpdatedAt = req.CreatedAt
+	return image, nil
+}
+
+// importer
+// @description: 取り込んだものの所有者になるユーザーを取得（退会したユーザーは使えない）
+func (u *importUseCase) importer(username string) (*domain.User, error) {
+	user, err := u.userRepo.GetByUsername(username)
+	if err != nil || !user.IsActive {
+		return nil, fmt.Errorf("user %q: %w", username, domain.ErrNotFound)
+	}
+	return user, nil
+}
+
+// uploadFile
+// @description: 画像ファイルを通常のアップロードと同じ処理で保存し、指定の公開範囲にする
+func (u *importUseCase) uploadFile(userID uint, title, description, tags, visibility, path string) (*domain.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	image, err := u.imageUseCase.UploadImage(userID, title, description, tags, file, filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	if visibility == image.Visibility {
+		return image, nil
+	}
+	return u.imageUseCase.UpdateImage(userID, image.ID, image.Title, image.Description, image.Tags, visibility)
+}
+
+// discardImages
+// @description: 取り込みを終えられなかったときに、アップロード済みの画像をゴミ箱に移す
+func (u *importUseCase) discardImages(userID uint, imageIDs []uint) {
+	for _, imageID := range imageIDs {
+		if err := u.imageUseCase.DeleteImage(userID, imageID); err != nil {
+			log.Printf("Failed to discard imported image %d: %v", imageID, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"backend/domain"
+	"fmt"
+	"log"
+)
+
+// notificationUseCase
+// @description: 通知ユースケースの実装
+type notificationUseCase struct {
+	notificationRepo domain.NotificationRepository
+	stream           domain.StreamHub
+}
+
+// NewNotificationUseCase
+// @description: 通知ユースケースを初期化
+func NewNotificationUseCase(notificationRepo domain.NotificationRepository, stream domain.StreamHub) domain.NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		stream:           stream,
+	}
+}
+
+// Notify
+// @description: 通知を保存する。自分の行動や受け取らない設定の種類は通知しない。
+// 通知は本来の処理のおまけなので、失敗してもログに残すだけにする
+func (u *notificationUseCase) Notify(event domain.NotificationEvent) {
+	if event.RecipientID == 0 || event.ActorID == event.RecipientID {
+		return
+	}
+
+	prefs, err := u.GetPreferences(event.RecipientID)
+	if err != nil {
+		log.Printf("Failed to load notification preferences of user %d: %v", event.RecipientID, err)
+		return
+	}
+	if !prefs[event.Type] {
+		return
+	}
+
+	if err := u.notificationRepo.Record(event); err != nil {
+		log.Printf("Failed to record %s notification for user %d: %v", event.Type, event.RecipientID, err)
+		return
+	}
+
+	// 接続中のクライアントに知らせる（中身は一覧を取り直してもらう）
+	unread, err := u.notificationRepo.CountUnread(event.RecipientID)
+	if err != nil {
+		log.Printf("Failed to count unread notifications of user %d: %v", event.RecipientID, err)
+		return
+	}
+	u.stream.PublishToUser(event.RecipientID, domain.StreamEventNotification, map[string]interface{}{
+		"type":         event.Type,
+		"target_type":  event.TargetType,
+		"target_id":    event.TargetID,
+		"unread_count": unread,
+	})
+}
+
+// GetNotifications
+// @description: 通知を新しい順に取得し、未読数と合わせて返す
+func (u *notificationUseCase) GetNotifications(userID uint, unreadOnly bool, page, limit int) (*domain.NotificationList, error) {
+	offset := (page - 1) * limit
+	notifications, err := u.notificationRepo.GetByUserID(userID, unreadOnly, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := u.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range notifications {
+		n.Message = notificationMessage(n)
+	}
+	return &domain.NotificationList{Data: notifications, UnreadCount: unread}, nil
+}
+
+// MarkRead
+// @description: 通知を既読にする
+func (u *notificationUseCase) MarkRead(userID, notificationID uint) error {
+	return u.notificationRepo.MarkRead(userID, notificationID)
+}
+
+// MarkAllRead
+// @description: すべての通知を既読にする
+func (u *notificationUseCase) MarkAllRead(userID uint) error {
+	return u.notificationRepo.MarkAllRead(userID)
+}
+
+// GetPreferences
+// @description: 種類ごとの受け取り設定を取得（設定していない種類は受け取る）
+func (u *notificationUseCase) GetPreferences(userID uint) (map[string]bool, error) {
+	saved, err := u.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make(map[string]bool, len(domain.NotificationTypes))
+	for _, t := range domain.NotificationTypes {
+		prefs[t] = true
+	}
+	for _, p := range saved {
+		if _, ok := prefs[p.Type]; ok {
+			prefs[p.Type] = p.Enabled
+		}
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences
+// @description: 受け取り設定を更新（含まれていない種類は変えない）
+func (u *notificationUseCase) UpdatePreferences(userID uint, prefs map[string]bool) (map[string]bool, error) {
+	current, err := u.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*domain.NotificationPreference, 0, len(prefs))
+	for t, enabled := range prefs {
+		if _, ok := current[t]; !ok {
+			return nil, domain.ErrInvalidInput
+		}
+		rows = append(rows, &domain.NotificationPreference{UserID: userID, Type: t, Enabled: enabled})
+	}
+
+	err = u.notificationRepo.SavePreferences(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for t, enabled := range prefs {
+		current[t] = enabled
+	}
+	return current, nil
+}
+
+// notificationMessage
+// @description: 一覧に表示する文面を作る（まとめた通知は「alice and 4 others liked your post」）
+func notificationMessage(n *domain.Notification) string {
+	actor := "Someone"
+	if n.Actor != nil {
+		actor = n.Actor.Username
+	}
+	switch others := n.ActorCount - 1; {
+	case others == 1:
+		actor += " and 1 other"
+	case others > 1:
+		actor += fmt.Sprintf(" and %d others", others)
+	}
+
+	switch n.Type {
+	case domain.NotificationFollow:
+		return actor + " followed you"
+	case domain.NotificationLike:
+		return actor + " liked your post"
+	case domain.NotificationComment:
+		return actor + " commented on your post"
+	case domain.NotificationPostPublished:
+		return "Your scheduled post was published"
+	default:
+		return ""
+	}
+}
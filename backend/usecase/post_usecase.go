@@ -3,28 +3,64 @@ package usecase
 import (
 	"backend/domain"
 	"fmt"
+	"log"
 	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// facetLimit
+// @description: タグ・投稿者ファセットで返す上位件数
+const facetLimit = 10
+
+// 制作過程の入力の上限
+const (
+	maxPostStages    = 20   // 1つの投稿の段階数
+	maxStageLabelLen = 50   // 段階の名前の文字数
+	maxStageNoteLen  = 1000 // 段階のメモの文字数
 )
 
 // postUseCase
 // @description: 投稿ユースケースの実装
 type postUseCase struct {
-	postRepo  domain.PostRepository
-	imageRepo domain.ImageRepository
+	postRepo      domain.PostRepository
+	imageRepo     domain.ImageRepository
+	bookmarkRepo  domain.BookmarkRepository
+	notifications domain.NotificationUseCase
+	stream        domain.StreamHub
+	webhooks      domain.WebhookUseCase
 }
 
 // NewPostUseCase
 // @description: 投稿ユースケースを初期化
-func NewPostUseCase(postRepo domain.PostRepository, imageRepo domain.ImageRepository) domain.PostUseCase {
+func NewPostUseCase(
+	postRepo domain.PostRepository,
+	imageRepo domain.ImageRepository,
+	bookmarkRepo domain.BookmarkRepository,
+	notifications domain.NotificationUseCase,
+	stream domain.StreamHub,
+	webhooks domain.WebhookUseCase,
+) domain.PostUseCase {
 	return &postUseCase{
-		postRepo:  postRepo,
-		imageRepo: imageRepo,
+		postRepo:      postRepo,
+		imageRepo:     imageRepo,
+		bookmarkRepo:  bookmarkRepo,
+		notifications: notifications,
+		stream:        stream,
+		webhooks:      webhooks,
 	}
 }
 
 // CreatePost
 // @description: 投稿を作成
-func (u *postUseCase) CreatePost(userID uint, title, description string, imageIDs []uint, tags string) (*domain.Post, error) {
+func (u *postUseCase) CreatePost(userID uint, title, description string, imageIDs []uint, tags, visibility string, stageReqs []domain.PostStageRequest, status string, publishAt *time.Time) (*domain.Post, error) {
+	if visibility == "" {
+		visibility = domain.VisibilityPublic
+	}
+	if !domain.ValidVisibility(visibility) {
+		return nil, domain.ErrInvalidInput
+	}
+
 	// すべての画像がユーザーのものかどうかを確認
 	for _, imageID := range imageIDs {
 		image, err := u.imageRepo.GetByID(imageID)
@@ -36,20 +72,33 @@ func (u *postUseCase) CreatePost(userID uint, title, description string, imageID
 		}
 	}
 
+	stages, err := u.buildStages(userID, stageReqs)
+	if err != nil {
+		return nil, err
+	}
+
 	// 投稿を作成
 	post := &domain.Post{
 		UserID:      userID,
 		Title:       title,
 		Description: description,
 		Tags:        tags,
-		IsPublic:    true,
+		Stages:      stages,
+		Visibility:  visibility,
 		ViewCount:   0,
 	}
+	if status == "" {
+		status = domain.PostStatusPublished
+	}
+	if err := setPublication(post, status, publishAt, time.Now()); err != nil {
+		return nil, err
+	}
 
-	err := u.postRepo.Create(post)
+	err = u.postRepo.Create(post)
 	if err != nil {
 		return nil, err
 	}
+	u.announcePost(post)
 
 	// Associate images with post
 	// Note: This would require updating the post_images many-to-many relationship
@@ -60,13 +109,23 @@ func (u *postUseCase) CreatePost(userID uint, title, description string, imageID
 }
 
 // GetPost
-// @description: 投稿をIDで取得
-func (u *postUseCase) GetPost(postID uint) (*domain.Post, error) {
+// @description: 投稿をIDで取得（閲覧できない投稿は存在しないものとして扱い、投稿内の閲覧できない画像は除く）
+func (u *postUseCase) GetPost(viewerID, postID uint) (*domain.Post, error) {
 	post, err := u.postRepo.GetByID(postID)
 	if err != nil {
 		return nil, err
 	}
 
+	if !post.VisibleTo(viewerID) {
+		return nil, domain.ErrNotFound
+	}
+	hideInvisibleImages(post, viewerID)
+	if post.UserID == viewerID {
+		if err := u.fillBookmarkCounts([]*domain.Post{post}); err != nil {
+			return nil, err
+		}
+	}
+
 	// 閲覧数を増やす
 	go u.postRepo.IncrementViewCount(postID)
 
@@ -77,19 +136,80 @@ func (u *postUseCase) GetPost(postID uint) (*domain.Post, error) {
 // @description: ユーザーIDで投稿を取得
 func (u *postUseCase) GetUserPosts(userID uint, page, limit int) ([]*domain.Post, error) {
 	offset := (page - 1) * limit
-	return u.postRepo.GetByUserID(userID, offset, limit)
+	posts, err := u.postRepo.GetByUserID(userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.fillBookmarkCounts(posts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// fillBookmarkCounts
+// @description: 所有者向けにブックマーク数を設定する（誰がブックマークしたかは返さない）
+func (u *postUseCase) fillBookmarkCounts(posts []*domain.Post) error {
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	counts, err := u.bookmarkRepo.CountByItems(domain.BookmarkItemPost, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		count := counts[post.ID]
+		post.BookmarkCount = &count
+	}
+	return nil
 }
 
 // GetPublicPosts
-// @description: 公開投稿を取得
-func (u *postUseCase) GetPublicPosts(page, limit int) ([]*domain.Post, error) {
+// @description: 閲覧者の一覧に出る公開済みの投稿を取得（ログイン中はメンバー限定の投稿も含む）
+func (u *postUseCase) GetPublicPosts(viewerID uint, page, limit int) ([]*domain.Post, error) {
 	offset := (page - 1) * limit
-	return u.postRepo.GetPublic(offset, limit)
+	posts, err := u.postRepo.GetPublic(viewerID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		hideInvisibleImages(post, viewerID)
+	}
+	return posts, nil
+}
+
+// hideInvisibleImages
+// @description: 投稿に含まれる画像のうち、閲覧者が見られないもの（個別に非公開にした画像など）を除く
+func hideInvisibleImages(post *domain.Post, viewerID uint) {
+	images := post.Images[:0]
+	for _, image := range post.Images {
+		if image.VisibleTo(viewerID) {
+			images = append(images, image)
+		}
+	}
+	post.Images = images
+
+	stages := post.Stages[:0]
+	for _, stage := range post.Stages {
+		if stage.Image != nil && stage.Image.VisibleTo(viewerID) {
+			stages = append(stages, stage)
+		}
+	}
+	post.Stages = stages
 }
 
 // UpdatePost
 // @description: 投稿を更新
-func (u *postUseCase) UpdatePost(userID, postID uint, title, description, tags string, isPublic bool) (*domain.Post, error) {
+func (u *postUseCase) UpdatePost(userID, postID uint, title, description, tags, visibility string, status string, publishAt *time.Time) (*domain.Post, error) {
+	if visibility != "" && !domain.ValidVisibility(visibility) {
+		return nil, domain.ErrInvalidInput
+	}
+
 	post, err := u.postRepo.GetByID(postID)
 	if err != nil {
 		return nil, err
@@ -100,15 +220,26 @@ func (u *postUseCase) UpdatePost(userID, postID uint, title, description, tags s
 		return nil, domain.ErrForbidden
 	}
 
+	fromStatus := post.Status
 	post.Title = title
 	post.Description = description
 	post.Tags = tags
-	post.IsPublic = isPublic
+	if visibility != "" {
+		post.Visibility = visibility
+	}
+
+	if status != "" {
+		if err := setPublication(post, status, publishAt, time.Now()); err != nil {
+			return nil, err
+		}
+	}
 
-	err = u.postRepo.Update(post)
+	// 読み込んだ後に予約投稿が公開されていたら、古い状態で上書きしない
+	err = u.postRepo.Update(post, fromStatus)
 	if err != nil {
 		return nil, err
 	}
+	u.announcePost(post)
 
 	return post, nil
 }
@@ -129,18 +260,208 @@ func (u *postUseCase) DeletePost(userID, postID uint) error {
 	return u.postRepo.Delete(postID)
 }
 
+// SetPostStages
+// @description: 投稿の制作過程を指定された順番で設定する（空にすると制作過程を消す）
+func (u *postUseCase) SetPostStages(userID, postID uint, stageReqs []domain.PostStageRequest) (*domain.Post, error) {
+	post, err := u.postRepo.GetByID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ユーザーが投稿の所有者かどうかを確認
+	if post.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
+	stages, err := u.buildStages(userID, stageReqs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.postRepo.ReplaceStages(postID, stages)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.postRepo.GetByID(postID)
+}
+
+// buildStages
+// @description: 制作過程の入力を検証する（画像は投稿者自身のものに限る）
+func (u *postUseCase) buildStages(userID uint, stageReqs []domain.PostStageRequest) ([]domain.PostStage, error) {
+	if len(stageReqs) > maxPostStages {
+		return nil, domain.ErrInvalidInput
+	}
+
+	stages := make([]domain.PostStage, 0, len(stageReqs))
+	for i, req := range stageReqs {
+		label := strings.TrimSpace(req.Label)
+		note := strings.TrimSpace(req.Note)
+		if label == "" || utf8.RuneCountInString(label) > maxStageLabelLen || utf8.RuneCountInString(note) > maxStageNoteLen {
+			return nil, domain.ErrInvalidInput
+		}
+
+		image, err := u.imageRepo.GetByID(req.ImageID)
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrInvalidInput
+		}
+		if err != nil {
+			return nil, err
+		}
+		if image.UserID != userID {
+			return nil, domain.ErrForbidden
+		}
+
+		stages = append(stages, domain.PostStage{
+			Position: i,
+			ImageID:  req.ImageID,
+			Label:    label,
+			Note:     note,
+		})
+	}
+	return stages, nil
+}
+
+// setPublication
+// @description: 投稿の公開状態を変更する。予約は未来の日時が必要で、
+// すでに公開済みの投稿を公開し直しても公開日時は変えない
+func setPublication(post *domain.Post, status string, publishAt *time.Time, now time.Time) error {
+	switch status {
+	case domain.PostStatusDraft:
+		post.PublishAt = nil
+	case domain.PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return domain.ErrInvalidInput
+		}
+		post.PublishAt = publishAt
+	case domain.PostStatusPublished:
+		if post.Status != domain.PostStatusPublished || post.PublishAt == nil {
+			post.PublishAt = &now
+		}
+	default:
+		return domain.ErrInvalidInput
+	}
+	post.Status = status
+	return nil
+}
+
+// isPubliclyListed
+// @description: 誰でも見られる一覧に出る投稿かどうか
+func isPubliclyListed(post *domain.Post) bool {
+	return post.Status == domain.PostStatusPublished && post.Visibility == domain.VisibilityPublic
+}
+
+// announcePost
+// @description: 初めて一般公開された投稿をストリームとWebhookで知らせる
+// （非公開にしてから公開し直しても、もう一度は知らせない）
+func (u *postUseCase) announcePost(post *domain.Post) {
+	if !isPubliclyListed(post) || post.AnnouncedAt != nil {
+		return
+	}
+	first, err := u.postRepo.MarkAnnounced(post.ID, time.Now())
+	if err != nil {
+		log.Printf("Failed to mark post %d as announced: %v", post.ID, err)
+		return
+	}
+	if !first {
+		return
+	}
+	u.stream.PublishToTopic(domain.StreamTopicPublicPosts, domain.StreamEventPostPublished, map[string]interface{}{
+		"id":         post.ID,
+		"user_id":    post.UserID,
+		"title":      post.Title,
+		"publish_at": post.PublishAt,
+	})
+
+	// 投稿者名と画像を載せるため読み込み直す
+	if full, err := u.postRepo.GetByID(post.ID); err == nil {
+		post = full
+	}
+	event := domain.WebhookEvent{
+		Type:        domain.WebhookEventPostPublished,
+		Title:       post.Title,
+		Description: post.Description,
+		URL:         fmt.Sprintf("/posts/%d", post.ID),
+		Author:      post.User.Username,
+		Data: map[string]interface{}{
+			"id":          post.ID,
+			"title":       post.Title,
+			"description": post.Description,
+			"tags":        post.Tags,
+			"user_id":     post.UserID,
+			"username":    post.User.Username,
+			"publish_at":  post.PublishAt,
+		},
+	}
+	if post.PublishAt != nil {
+		event.OccurredAt = *post.PublishAt
+	}
+	for _, image := range post.Images {
+		if image.Visibility == domain.VisibilityPublic {
+			event.ImageURL = image.URL
+			break
+		}
+	}
+	u.webhooks.Dispatch(event)
+}
+
+// PublishScheduledPosts
+// @description: 公開日時を過ぎた予約投稿を公開する（バックグラウンドで定期的に実行）
+func (u *postUseCase) PublishScheduledPosts() error {
+	posts, err := u.postRepo.PublishDue(time.Now())
+	if err != nil {
+		return err
+	}
+	if len(posts) > 0 {
+		log.Printf("Published %d scheduled posts", len(posts))
+	}
+
+	for _, post := range posts {
+		u.announcePost(post)
+		u.notifications.Notify(domain.NotificationEvent{
+			Type:        domain.NotificationPostPublished,
+			RecipientID: post.UserID,
+			TargetType:  domain.NotificationTargetPost,
+			TargetID:    post.ID,
+		})
+	}
+	return nil
+}
+
 // SearchPosts
-// @description: 投稿をクエリで検索
-func (u *postUseCase) SearchPosts(query string, page, limit int) ([]*domain.Post, error) {
+// @description: 投稿を検索条件で検索（閲覧者が見られない画像は除く）
+func (u *postUseCase) SearchPosts(filter domain.SearchFilter, page, limit int) ([]*domain.Post, error) {
 	offset := (page - 1) * limit
-	return u.postRepo.Search(query, offset, limit)
+	posts, err := u.postRepo.Search(filter, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		hideInvisibleImages(post, filter.ViewerID)
+	}
+	return posts, nil
+}
+
+// GetPostFacets
+// @description: 検索条件に一致する投稿のタグ・投稿者・月別件数を取得
+func (u *postUseCase) GetPostFacets(filter domain.SearchFilter) (*domain.SearchFacets, error) {
+	return u.postRepo.Facets(filter, facetLimit)
 }
 
 // GetPostsByTags
-// @description: タグで投稿を取得
+// @description: タグで公開投稿を取得（ログインなしで見られない画像は除く）
 func (u *postUseCase) GetPostsByTags(tags []string, page, limit int) ([]*domain.Post, error) {
 	offset := (page - 1) * limit
-	return u.postRepo.GetByTags(tags, offset, limit)
+	posts, err := u.postRepo.GetByTags(tags, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		hideInvisibleImages(post, 0)
+	}
+	return posts, nil
 }
 
 // IncrementViewCount
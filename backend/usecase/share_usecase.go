@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"backend/domain"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 共有リンクの有効期間
+const (
+	defaultShareTTL = 7 * 24 * time.Hour  // 指定がない場合
+	maxShareTTL     = 30 * 24 * time.Hour // 上限
+)
+
+// shareUseCase
+// @description: 共有リンクユースケースの実装
+type shareUseCase struct {
+	shareRepo domain.ShareLinkRepository
+	imageRepo domain.ImageRepository
+	postRepo  domain.PostRepository
+}
+
+// NewShareUseCase
+// @description: 共有リンクユースケースを初期化
+func NewShareUseCase(shareRepo domain.ShareLinkRepository, imageRepo domain.ImageRepository, postRepo domain.PostRepository) domain.ShareUseCase {
+	return &shareUseCase{
+		shareRepo: shareRepo,
+		imageRepo: imageRepo,
+		postRepo:  postRepo,
+	}
+}
+
+// CreateShare
+// @description: 作品の共有リンクを発行（ttlが0の場合は既定の期間）
+func (u *shareUseCase) CreateShare(userID uint, targetType string, targetID uint, ttl time.Duration) (*domain.ShareLink, error) {
+	if ttl == 0 {
+		ttl = defaultShareTTL
+	}
+	if ttl < 0 || ttl > maxShareTTL {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if err := u.checkOwner(userID, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	share := &domain.ShareLink{
+		UserID:     userID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		// トークンには秒単位で埋め込むので揃えておく
+		ExpiresAt: time.Now().Add(ttl).Truncate(time.Second),
+	}
+	if err := u.shareRepo.Create(share); err != nil {
+		return nil, err
+	}
+
+	share.Token = signShareToken(share.ID, share.ExpiresAt)
+	share.URL = "/s/" + share.Token
+	return share, nil
+}
+
+// GetShares
+// @description: 作品の共有リンクを取得（トークンは含めない）
+func (u *shareUseCase) GetShares(userID uint, targetType string, targetID uint) ([]*domain.ShareLink, error) {
+	if err := u.checkOwner(userID, targetType, targetID); err != nil {
+		return nil, err
+	}
+	return u.shareRepo.GetByTarget(targetType, targetID)
+}
+
+// RevokeShare
+// @description: 共有リンクを無効にする（発行したユーザーのみ）
+func (u *shareUseCase) RevokeShare(userID, shareID uint) error {
+	share, err := u.shareRepo.GetByID(shareID)
+	if err != nil {
+		return err
+	}
+
+	if share.UserID != userID {
+		return domain.ErrForbidden
+	}
+
+	return u.shareRepo.Revoke(shareID, time.Now())
+}
+
+// ResolveShare
+// @description: 署名と期限を検証してから共有された作品を取得し、閲覧数を数える。
+// 共有リンクは公開範囲に関係なく作品を見せるが、ゴミ箱に入った作品は見せない
+func (u *shareUseCase) ResolveShare(token string) (*domain.SharedItem, error) {
+	now := time.Now()
+	shareID, expiresAt, ok := verifyShareToken(token)
+	if !ok || !now.Before(expiresAt) {
+		return nil, domain.ErrNotFound
+	}
+
+	share, err := u.shareRepo.RecordView(shareID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &domain.SharedItem{Type: share.TargetType, ExpiresAt: share.ExpiresAt}
+	switch share.TargetType {
+	case domain.ShareTargetImage:
+		item.Image, err = u.imageRepo.GetByID(share.TargetID)
+	case domain.ShareTargetPost:
+		item.Post, err = u.postRepo.GetByID(share.TargetID)
+	default:
+		err = domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// checkOwner
+// @description: ユーザーが共有する作品の所有者かどうかを確認
+func (u *shareUseCase) checkOwner(userID uint, targetType string, targetID uint) error {
+	var ownerID uint
+	switch targetType {
+	case domain.ShareTargetImage:
+		image, err := u.imageRepo.GetByID(targetID)
+		if err != nil {
+			return err
+		}
+		ownerID = image.UserID
+	case domain.ShareTargetPost:
+		post, err := u.postRepo.GetByID(targetID)
+		if err != nil {
+			return err
+		}
+		ownerID = post.UserID
+	default:
+		return domain.ErrInvalidInput
+	}
+
+	if ownerID != userID {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// signShareToken
+// @description: 共有リンクのIDと期限に署名したトークンを生成（<id>.<期限のUnix秒>.<署名>）
+func signShareToken(shareID uint, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", shareID, expiresAt.Unix())
+	return payload + "." + shareSignature(payload)
+}
+
+// verifyShareToken
+// @description: トークンの署名を検証してIDと期限を取り出す
+func verifyShareToken(token string) (uint, time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, time.Time{}, false
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(shareSignature(payload))) {
+		return 0, time.Time{}, false
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return uint(id), time.Unix(expires, 0), true
+}
+
+// shareSignature
+// @description: 共有リンクのトークンの署名（変換URLの署名と混同しないように用途を含める）
+func shareSignature(payload string) string {
+	mac := hmac.New(sha256.New, urlSigningSecret())
+	fmt.Fprintf(mac, "share|%s", payload)
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
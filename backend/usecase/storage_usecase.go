@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"backend/domain"
+	"backend/infrastructure/cloudinary"
+	"backend/infrastructure/imaging"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"time"
+)
+
+// ストレージ操作の再試行の設定
+const (
+	outboxBatchSize   = 50
+	outboxLease       = 5 * time.Minute // 実行中の操作を他のワーカーに渡さない時間
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = 6 * time.Hour
+	outboxMaxAttempts = 12
+)
+
+// orphanGracePeriod
+// @description: アップロード中のファイルを孤立したものと誤判定しないよう、これより新しいファイルは対象外にする
+const orphanGracePeriod = time.Hour
+
+// storagePrefix
+// @description: このアプリケーションが画像を保存するPublic IDの接頭辞
+const storagePrefix = "images/"
+
+// storageUseCase
+// @description: ストレージとDBの整合性を保つ処理の実装
+type storageUseCase struct {
+	outboxRepo    domain.OutboxRepository
+	blobRepo      domain.BlobRepository
+	cloudinarySvc *cloudinary.Service
+	variantGen    *imaging.Generator
+	purgeOrphans  bool
+}
+
+// NewStorageUseCase
+// @description: ストレージユースケースを初期化
+// STORAGE_RECONCILE_MODE が purge の場合は孤立したファイルを削除し、それ以外（既定の report）はログに出力するだけにする
+func NewStorageUseCase(outboxRepo domain.OutboxRepository, blobRepo domain.BlobRepository) domain.StorageUseCase {
+	cloudinarySvc, err := cloudinary.NewService()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize Cloudinary service: %v", err))
+	}
+
+	variantGen, err := imaging.NewGenerator()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize image variant generator: %v", err))
+	}
+
+	mode := os.Getenv("STORAGE_RECONCILE_MODE")
+	if mode != "" && mode != "report" && mode != "purge" {
+		panic(fmt.Sprintf("Invalid STORAGE_RECONCILE_MODE: %q", mode))
+	}
+
+	return &storageUseCase{
+		outboxRepo:    outboxRepo,
+		blobRepo:      blobRepo,
+		cloudinarySvc: cloudinarySvc,
+		variantGen:    variantGen,
+		purgeOrphans:  mode == "purge",
+	}
+}
+
+// ProcessOutbox
+// @description: 実行時刻になったストレージ操作を実行し、失敗したものは指数バックオフで再試行する
+func (u *storageUseCase) ProcessOutbox() error {
+	ctx := context.Background()
+	for {
+		tasks, err := u.outboxRepo.Claim(time.Now(), outboxLease, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+
+		for _, task := range tasks {
+			if err := u.runTask(ctx, task); err != nil {
+				if err := u.retryLater(task, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := u.outboxRepo.Complete(task.ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// runTask
+// @description: ストレージ操作を1つ実行
+func (u *storageUseCase) runTask(ctx context.Context, task *domain.OutboxTask) error {
+	switch task.Action {
+	case domain.OutboxActionDeleteImage:
+		// Blobのファイルは作成ごとに別のPublic IDなので再利用されないが、記録の誤りで使用中のファイルを消さないよう確認する
+		referenced, err := u.outboxRepo.IsReferenced(task.PublicID)
+		if err != nil || referenced {
+			return err
+		}
+		return u.cloudinarySvc.DeleteImage(ctx, task.PublicID)
+	case domain.OutboxActionGenerateVariants:
+		return u.generateVariants(ctx, task.PublicID)
+	default:
+		return fmt.Errorf("unknown outbox action: %s", task.Action)
+	}
+}
+
+// generateVariants
+// @description: Blobの元画像から縮小版を作り直し、Blobとそれを使う画像に設定する（Blobが削除済みなら何もしない）
+func (u *storageUseCase) generateVariants(ctx context.Context, publicID string) error {
+	blob, err := u.blobRepo.GetByCloudinaryID(publicID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(blob.Variants) > 0 {
+		return nil
+	}
+
+	body, err := u.cloudinarySvc.DownloadImage(ctx, blob.URL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	src, _, err := imaging.Decode(io.LimitReader(body, maxOriginalBytes))
+	if err != nil {
+		return fmt.Errorf("failed to decode original image: %w", err)
+	}
+
+	// 縮小版の名前は元画像から決まり、再試行では同じPublic IDに上書きするので、
+	// 途中まで上げたものは削除しない（再試行の上限に達した場合は整合性チェックで見つかる）
+	variants, err := uploadVariants(ctx, u.cloudinarySvc, u.variantGen, src, path.Base(publicID))
+	if err != nil {
+		return err
+	}
+
+	err = u.blobRepo.SetVariants(publicID, variants)
+	if errors.Is(err, domain.ErrNotFound) {
+		// 作り直している間にBlobが削除された
+		tasks := make([]*domain.OutboxTask, 0, len(variants))
+		for _, variant := range variants {
+			tasks = append(tasks, &domain.OutboxTask{Action: domain.OutboxActionDeleteImage, PublicID: variant.CloudinaryID})
+		}
+		return u.outboxRepo.Enqueue(tasks...)
+	}
+	return err
+}
+
+// retryLater
+// @description: 失敗を記録して次の実行時刻を決める。上限に達したものは failed にして残す
+func (u *storageUseCase) retryLater(task *domain.OutboxTask, cause error) error {
+	task.Attempts++
+	task.LastError = cause.Error()
+
+	if task.Attempts >= outboxMaxAttempts {
+		task.Status = domain.OutboxStatusFailed
+		log.Printf("Storage task %d (%s %s) failed permanently: %v", task.ID, task.Action, task.PublicID, cause)
+	} else {
+		backoff := outboxBaseBackoff << (task.Attempts - 1)
+		if backoff > outboxMaxBackoff {
+			backoff = outboxMaxBackoff
+		}
+		task.NextAttemptAt = time.Now().Add(backoff)
+	}
+
+	return u.outboxRepo.Update(task)
+}
+
+// ReconcileStorage
+// @description: ストレージ上の画像を一覧し、画像・Blob・削除待ちのどれにも含まれないものを報告または削除する
+func (u *storageUseCase) ReconcileStorage() error {
+	known := map[string]bool{}
+	referenced, err := u.outboxRepo.ReferencedPublicIDs()
+	if err != nil {
+		return err
+	}
+	pending, err := u.outboxRepo.PendingPublicIDs()
+	if err != nil {
+		return err
+	}
+	for _, id := range append(referenced, pending...) {
+		known[id] = true
+	}
+
+	ctx := context.Background()
+	cutoff := time.Now().Add(-orphanGracePeriod)
+	var orphans []*domain.OutboxTask
+	cursor := ""
+	for {
+		images, next, err := u.cloudinarySvc.ListImages(ctx, storagePrefix, cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, image := range images {
+			if known[image.PublicID] || image.CreatedAt.After(cutoff) {
+				continue
+			}
+			if !u.purgeOrphans {
+				log.Printf("Orphaned image in storage: %s", image.PublicID)
+			}
+			orphans = append(orphans, &domain.OutboxTask{Action: domain.OutboxActionDeleteImage, PublicID: image.PublicID})
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if !u.purgeOrphans || len(orphans) == 0 {
+		log.Printf("Storage reconciliation found %d orphaned images", len(orphans))
+		return nil
+	}
+
+	log.Printf("Storage reconciliation queued %d orphaned images for deletion", len(orphans))
+	return u.outboxRepo.Enqueue(orphans...)
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"backend/domain"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// defaultTrashRetention
+// @description: 削除した画像・投稿を元に戻せる期間（TRASH_RETENTIONで上書き可能）
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// trashPurgeBatchSize
+// @description: 一度に完全に削除する件数
+const trashPurgeBatchSize = 100
+
+// trashUseCase
+// @description: ゴミ箱ユースケースの実装
+type trashUseCase struct {
+	imageRepo domain.ImageRepository
+	postRepo  domain.PostRepository
+	retention time.Duration
+}
+
+// NewTrashUseCase
+// @description: ゴミ箱ユースケースを初期化
+func NewTrashUseCase(imageRepo domain.ImageRepository, postRepo domain.PostRepository) domain.TrashUseCase {
+	retention := defaultTrashRetention
+	if env := os.Getenv("TRASH_RETENTION"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil || d <= 0 {
+			panic(fmt.Sprintf("Invalid TRASH_RETENTION: %q", env))
+		}
+		retention = d
+	}
+
+	return &trashUseCase{
+		imageRepo: imageRepo,
+		postRepo:  postRepo,
+		retention: retention,
+	}
+}
+
+// GetTrash
+// @description: ゴミ箱の中身を削除が新しい順に取得
+func (u *trashUseCase) GetTrash(userID uint, itemType string, page, limit int) ([]*domain.TrashItem, error) {
+	if itemType != "" && itemType != domain.TrashTypeImages && itemType != domain.TrashTypePosts {
+		return nil, domain.ErrInvalidInput
+	}
+
+	// 画像と投稿を混ぜて並べるため、それぞれ先頭からページの終わりまで取得する
+	offset := (page - 1) * limit
+	deletedAfter := time.Now().Add(-u.retention)
+	var items []*domain.TrashItem
+
+	if itemType != domain.TrashTypePosts {
+		images, err := u.imageRepo.GetDeletedByUserID(userID, deletedAfter, offset+limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			items = append(items, u.imageItem(image))
+		}
+	}
+
+	if itemType != domain.TrashTypeImages {
+		posts, err := u.postRepo.GetDeletedByUserID(userID, deletedAfter, offset+limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			items = append(items, u.postItem(post))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	if offset >= len(items) {
+		return []*domain.TrashItem{}, nil
+	}
+	return items[offset:min(offset+limit, len(items))], nil
+}
+
+// Restore
+// @description: ゴミ箱から元に戻す（保持期間を過ぎたものは戻せない）
+func (u *trashUseCase) Restore(userID uint, itemType string, id uint) (*domain.TrashItem, error) {
+	var item *domain.TrashItem
+	var ownerID uint
+	var restore func(id uint) error
+
+	switch itemType {
+	case domain.TrashTypeImages:
+		image, err := u.imageRepo.GetDeletedByID(id)
+		if err != nil {
+			return nil, err
+		}
+		item, ownerID, restore = u.imageItem(image), image.UserID, u.imageRepo.Restore
+	case domain.TrashTypePosts:
+		post, err := u.postRepo.GetDeletedByID(id)
+		if err != nil {
+			return nil, err
+		}
+		item, ownerID, restore = u.postItem(post), post.UserID, u.postRepo.Restore
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+
+	// 他人のゴミ箱は存在しないものとして扱う
+	if ownerID != userID || time.Now().After(item.PurgeAt) {
+		return nil, domain.ErrNotFound
+	}
+
+	if err := restore(id); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// PurgeExpired
+// @description: 保持期間を過ぎた画像・投稿を完全に削除（画像のファイルの削除はストレージ操作として記録される）
+func (u *trashUseCase) PurgeExpired() error {
+	deletedBefore := time.Now().Add(-u.retention)
+
+	for {
+		n, err := u.postRepo.PurgeDeleted(deletedBefore, trashPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Printf("Purged %d posts from the trash", n)
+		}
+		if n < trashPurgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		n, err := u.imageRepo.PurgeDeleted(deletedBefore, trashPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Printf("Purged %d images from the trash", n)
+		}
+		if n < trashPurgeBatchSize {
+			return nil
+		}
+	}
+}
+
+// imageItem
+// @description: 画像をゴミ箱の項目にする
+func (u *trashUseCase) imageItem(image *domain.Image) *domain.TrashItem {
+	return &domain.TrashItem{
+		Type:      domain.TrashTypeImages,
+		ID:        image.ID,
+		Title:     image.Title,
+		DeletedAt: image.DeletedAt.Time,
+		PurgeAt:   image.DeletedAt.Time.Add(u.retention),
+		Item:      image,
+	}
+}
+
+// postItem
+// @description: 投稿をゴミ箱の項目にする
+func (u *trashUseCase) postItem(post *domain.Post) *domain.TrashItem {
+	return &domain.TrashItem{
+		Type:      domain.TrashTypePosts,
+		ID:        post.ID,
+		Title:     post.Title,
+		DeletedAt: post.DeletedAt.Time,
+		PurgeAt:   post.DeletedAt.Time.Add(u.retention),
+		Item:      post,
+	}
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"backend/domain"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultUploadExpiry
+// @description: 最後の書き込みからアップロードを保持する期間（UPLOAD_EXPIRYで上書き可能）
+const defaultUploadExpiry = 24 * time.Hour
+
+// uploadLockLease
+// @description: 書き込みロックの有効期間。1回の書き込みより長くし、落ちたサーバーのロックはこれで外れる
+const uploadLockLease = time.Hour
+
+// uploadUseCase
+// @description: 再開可能アップロードユースケースの実装
+type uploadUseCase struct {
+	uploadRepo   domain.UploadRepository
+	imageUseCase domain.ImageUseCase
+	dir          string
+	expiry       time.Duration
+}
+
+// NewUploadUseCase
+// @description: 再開可能アップロードユースケースを初期化
+func NewUploadUseCase(uploadRepo domain.UploadRepository, imageUseCase domain.ImageUseCase) domain.UploadUseCase {
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "image-gallery-uploads")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		panic(fmt.Sprintf("Failed to create upload directory: %v", err))
+	}
+
+	expiry := defaultUploadExpiry
+	if env := os.Getenv("UPLOAD_EXPIRY"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil || d <= 0 {
+			panic(fmt.Sprintf("Invalid UPLOAD_EXPIRY: %q", env))
+		}
+		expiry = d
+	}
+
+	return &uploadUseCase{
+		uploadRepo:   uploadRepo,
+		imageUseCase: imageUseCase,
+		dir:          dir,
+		expiry:       expiry,
+	}
+}
+
+// CreateUpload
+// @description: アップロードを開始して空のファイルを作成
+func (u *uploadUseCase) CreateUpload(userID uint, length int64, metadata map[string]string) (*domain.Upload, error) {
+	if length <= 0 || metadata["filename"] == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	if length > MaxUploadBytes() {
+		return nil, domain.ErrFileTooLarge
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &domain.Upload{
+		ID:          id,
+		UserID:      userID,
+		Length:      length,
+		Filename:    metadata["filename"],
+		Title:       metadata["title"],
+		Description: metadata["description"],
+		Tags:        metadata["tags"],
+		ExpiresAt:   time.Now().Add(u.expiry),
+	}
+
+	file, err := os.Create(u.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	file.Close()
+
+	if err := u.uploadRepo.Create(upload); err != nil {
+		os.Remove(u.path(id))
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// GetUpload
+// @description: アップロードの状態を取得
+func (u *uploadUseCase) GetUpload(userID uint, uploadID string) (*domain.Upload, error) {
+	upload, err := u.uploadRepo.GetByID(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 他人のアップロードは存在しないものとして扱う
+	if upload.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+
+	return upload, nil
+}
+
+// WriteChunk
+// @description: offsetの位置からデータを書き込み、全体が揃ったら画像を作成
+func (u *uploadUseCase) WriteChunk(userID uint, uploadID string, offset int64, r io.Reader) (*domain.Upload, error) {
+	unlock, err := u.lock(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	upload, err := u.GetUpload(userID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.ImageID != nil {
+		return upload, nil
+	}
+	if upload.Offset != offset {
+		return upload, domain.ErrConflict
+	}
+
+	file, err := os.OpenFile(u.path(uploadID), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+
+	// 途中で切断されても受け取った分は保存する
+	written, copyErr := io.Copy(io.NewOffsetWriter(file, offset), io.LimitReader(r, upload.Length-offset))
+	closeErr := file.Close()
+	if closeErr != nil {
+		return nil, fmt.Errorf("failed to write upload file: %w", closeErr)
+	}
+
+	upload.Offset += written
+	upload.ExpiresAt = time.Now().Add(u.expiry)
+	if err := u.uploadRepo.Update(upload); err != nil {
+		return nil, err
+	}
+	if copyErr != nil {
+		return upload, fmt.Errorf("failed to receive upload data: %w", copyErr)
+	}
+
+	if upload.Offset < upload.Length {
+		return upload, nil
+	}
+
+	return upload, u.complete(upload)
+}
+
+// complete
+// @description: 揃ったファイルを画像ユースケースに渡して画像を作成
+func (u *uploadUseCase) complete(upload *domain.Upload) error {
+	file, err := os.Open(u.path(upload.ID))
+	if err != nil {
+		return fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	image, err := u.imageUseCase.UploadImage(upload.UserID, upload.Title, upload.Description, upload.Tags, file, upload.Filename)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidFileType) || errors.Is(err, domain.ErrImageTooLarge) || errors.Is(err, domain.ErrFileTooLarge) {
+			// 再送しても結果は変わらないので破棄する
+			u.remove(upload.ID)
+		}
+		// それ以外は一時的な失敗として、同じオフセットへの空のPATCHで再試行できるよう残す
+		return err
+	}
+
+	upload.ImageID = &image.ID
+	if err := u.uploadRepo.Update(upload); err != nil {
+		return err
+	}
+
+	os.Remove(u.path(upload.ID))
+	return nil
+}
+
+// DeleteUpload
+// @description: アップロードを中止してファイルを削除
+func (u *uploadUseCase) DeleteUpload(userID uint, uploadID string) error {
+	unlock, err := u.lock(uploadID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := u.GetUpload(userID, uploadID); err != nil {
+		return err
+	}
+	return u.remove(uploadID)
+}
+
+// PurgeExpiredUploads
+// @description: 期限切れのアップロードを削除（未完了のものは途中のファイルも削除）
+func (u *uploadUseCase) PurgeExpiredUploads() error {
+	for {
+		uploads, err := u.uploadRepo.GetExpired(time.Now(), 100)
+		if err != nil {
+			return err
+		}
+		if len(uploads) == 0 {
+			return nil
+		}
+
+		for _, upload := range uploads {
+			if err := u.purgeExpired(upload.ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// purgeExpired
+// @description: 書き込みロックを取ってから、まだ期限切れであればアップロードを削除（書き込み中なら残す）
+func (u *uploadUseCase) purgeExpired(uploadID string) error {
+	unlock, err := u.lock(uploadID)
+	if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrLocked) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	upload, err := u.uploadRepo.GetByID(uploadID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// 一覧を取得した後に書き込みがあれば期限が延びている
+	if upload.ExpiresAt.After(time.Now()) {
+		return nil
+	}
+	return u.remove(uploadID)
+}
+
+// remove
+// @description: アップロードのレコードとファイルを削除（書き込みロックを取った状態で呼ぶ）
+func (u *uploadUseCase) remove(uploadID string) error {
+	if err := os.Remove(u.path(uploadID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete upload file: %w", err)
+	}
+	return u.uploadRepo.Delete(uploadID)
+}
+
+// lock
+// @description: アップロードの書き込みロックを取り、外す関数を返す。ロックはレコードに記録するので、
+// 複数のサーバーで動かしても同じアップロードを同時に書き換えない（他のリクエストが処理中ならErrLocked）
+func (u *uploadUseCase) lock(uploadID string) (func(), error) {
+	now := time.Now()
+	locked, err := u.uploadRepo.Lock(uploadID, now, now.Add(uploadLockLease))
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		if _, err := u.uploadRepo.GetByID(uploadID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrLocked
+	}
+
+	return func() {
+		// 削除した後は外すロックがない
+		if err := u.uploadRepo.Unlock(uploadID); err != nil {
+			fmt.Printf("Failed to unlock upload %s: %v\n", uploadID, err)
+		}
+	}, nil
+}
+
+// path
+// @description: アップロード中のファイルのパス
+func (u *uploadUseCase) path(uploadID string) string {
+	return filepath.Join(u.dir, uploadID)
+}
+
+// newUploadID
+// @description: 推測できないアップロードIDを生成
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
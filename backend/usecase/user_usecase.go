@@ -14,12 +14,13 @@ import (
 // @description: ユーザーユースケースの実装
 type userUseCase struct {
 	userRepo domain.UserRepository
+	webhooks domain.WebhookUseCase
 }
 
 // NewUserUseCase
 // @description: ユーザーユースケースを初期化
-func NewUserUseCase(userRepo domain.UserRepository) domain.UserUseCase {
-	return &userUseCase{userRepo: userRepo}
+func NewUserUseCase(userRepo domain.UserRepository, webhooks domain.WebhookUseCase) domain.UserUseCase {
+	return &userUseCase{userRepo: userRepo, webhooks: webhooks}
 }
 
 // Register
@@ -64,6 +65,21 @@ func (u *userUseCase) Register(email, username, password, firstName, lastName st
 		return nil, err
 	}
 
+	// メールアドレスは外部に送らない
+	u.webhooks.Dispatch(domain.WebhookEvent{
+		Type:        domain.WebhookEventUserRegistered,
+		Title:       "New member: " + user.Username,
+		Description: "Welcome " + user.Username + "!",
+		URL:         "/users/" + user.Username,
+		Author:      user.Username,
+		Data: map[string]interface{}{
+			"id":         user.ID,
+			"username":   user.Username,
+			"created_at": user.CreatedAt,
+		},
+		OccurredAt: user.CreatedAt,
+	})
+
 	return user, nil
 }
 
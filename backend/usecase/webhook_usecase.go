@@ -0,0 +1,438 @@
+package usecase
+
+import (
+	"backend/domain"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Webhookの送信の設定
+const (
+	webhookBatchSize     = 20
+	webhookTimeout       = 10 * time.Second
+	webhookLease         = webhookBatchSize*webhookTimeout + time.Minute // 送信中のものを他のワーカーに渡さない時間（一度に取得した分がすべてタイムアウトしても切れない長さ）
+	webhookBaseBackoff   = 30 * time.Second
+	webhookMaxBackoff    = 6 * time.Hour
+	webhookMaxAttempts   = 10
+	webhookLogRetention  = 30 * 24 * time.Hour // 送信履歴を残す期間
+	webhookMaxNameLen    = 100
+	webhookMaxErrorBytes = 512 // 送信履歴に残す応答本文の長さ
+)
+
+// Discordの埋め込みの上限
+const (
+	discordMaxTitleLen       = 256
+	discordMaxDescriptionLen = 4096
+	discordEmbedColor        = 0x5865F2
+)
+
+// webhookUseCase
+// @description: Webhookユースケースの実装
+type webhookUseCase struct {
+	webhookRepo domain.WebhookRepository
+	client      *http.Client
+	siteURL     string // 作品へのリンクの前に付けるサイトのURL
+}
+
+// NewWebhookUseCase
+// @description: Webhookユースケースを初期化
+// PUBLIC_SITE_URL を設定すると、送信内容に作品やユーザーのページへのリンクを含める
+func NewWebhookUseCase(webhookRepo domain.WebhookRepository) domain.WebhookUseCase {
+	return &webhookUseCase{
+		webhookRepo: webhookRepo,
+		client:      &http.Client{Timeout: webhookTimeout},
+		siteURL:     strings.TrimRight(os.Getenv("PUBLIC_SITE_URL"), "/"),
+	}
+}
+
+// Dispatch
+// @description: 出来事を購読している有効なWebhookごとに送信内容を作って記録する。
+// 送信はワーカーが行うので、呼び出し元は外部のサービスを待たない
+func (u *webhookUseCase) Dispatch(event domain.WebhookEvent) {
+	webhooks, err := u.webhookRepo.GetActive()
+	if err != nil {
+		log.Printf("Failed to load webhooks for %s: %v", event.Type, err)
+		return
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+		delivery, err := u.newDelivery(webhook, event)
+		if err != nil {
+			log.Printf("Failed to build %s payload for webhook %d: %v", event.Type, webhook.ID, err)
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := u.webhookRepo.EnqueueDeliveries(deliveries...); err != nil {
+		log.Printf("Failed to enqueue %s webhook deliveries: %v", event.Type, err)
+	}
+}
+
+// CreateWebhook
+// @description: Webhookを作成（署名の鍵は自動で作る）
+func (u *webhookUseCase) CreateWebhook(name, url, format string, events []string, active bool) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{Active: active}
+	if err := setWebhookConfig(webhook, name, url, format, events); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	webhook.Secret = hex.EncodeToString(secret)
+
+	err := u.webhookRepo.Create(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook
+// @description: Webhookの設定を更新（署名の鍵は変えない）
+func (u *webhookUseCase) UpdateWebhook(id uint, name, url, format string, events []string, active bool) (*domain.Webhook, error) {
+	webhook, err := u.webhookRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setWebhookConfig(webhook, name, url, format, events); err != nil {
+		return nil, err
+	}
+	webhook.Active = active
+
+	err = u.webhookRepo.Update(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook
+// @description: Webhookと送信履歴を削除
+func (u *webhookUseCase) DeleteWebhook(id uint) error {
+	return u.webhookRepo.Delete(id)
+}
+
+// GetWebhooks
+// @description: すべてのWebhookを取得
+func (u *webhookUseCase) GetWebhooks() ([]*domain.Webhook, error) {
+	return u.webhookRepo.GetAll()
+}
+
+// GetDeliveries
+// @description: Webhookの送信履歴を新しい順に取得
+func (u *webhookUseCase) GetDeliveries(webhookID uint, page, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := u.webhookRepo.GetByID(webhookID); err != nil {
+		return nil, err
+	}
+
+	offset := (page - 1) * limit
+	return u.webhookRepo.GetDeliveries(webhookID, offset, limit)
+}
+
+// PingWebhook
+// @description: 設定を確かめるための送信テストを記録（無効なWebhookにも送る）
+func (u *webhookUseCase) PingWebhook(id uint) (*domain.WebhookDelivery, error) {
+	webhook, err := u.webhookRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := u.newDelivery(webhook, domain.WebhookEvent{
+		Type:        domain.WebhookEventPing,
+		Title:       "Webhook test",
+		Description: fmt.Sprintf("%q is set up correctly.", webhook.Name),
+		Data:        map[string]interface{}{"webhook_id": webhook.ID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.webhookRepo.EnqueueDeliveries(delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// DeliverWebhooks
+// @description: 送信時刻になったものを送り、失敗したものは指数バックオフで再試行する
+func (u *webhookUseCase) DeliverWebhooks() error {
+	webhooks := map[uint]*domain.Webhook{}
+	for {
+		deliveries, err := u.webhookRepo.ClaimDeliveries(time.Now(), webhookLease, webhookBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		for _, delivery := range deliveries {
+			webhook, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				webhook, err = u.webhookRepo.GetByID(delivery.WebhookID)
+				if err == domain.ErrNotFound {
+					// 削除されたWebhookの送信は外部キーで一緒に消える
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				webhooks[delivery.WebhookID] = webhook
+			}
+
+			if !webhook.Active && delivery.Event != domain.WebhookEventPing {
+				delivery.Status = domain.WebhookDeliveryFailed
+				delivery.LastError = "webhook is disabled"
+				if err := u.webhookRepo.UpdateDelivery(delivery); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := u.deliver(webhook, delivery); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// PurgeDeliveries
+// @description: 保存期間を過ぎた送信履歴を削除
+func (u *webhookUseCase) PurgeDeliveries() error {
+	n, err := u.webhookRepo.PurgeDeliveries(time.Now().Add(-webhookLogRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("Purged %d webhook deliveries", n)
+	}
+	return nil
+}
+
+// deliver
+// @description: 1件送って結果を記録する。2xxは成功、408・429・5xxと通信エラーは再試行し、
+// それ以外の4xxは設定の誤りとみなして再試行しない
+func (u *webhookUseCase) deliver(webhook *domain.Webhook, delivery *domain.WebhookDelivery) error {
+	delivery.Attempts++
+
+	status, retryAfter, err := u.send(webhook, delivery)
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		log.Printf("Webhook delivery %d to webhook %d failed permanently: %v", delivery.ID, webhook.ID, err)
+	default:
+		delivery.LastError = err.Error()
+		backoff := webhookBaseBackoff << (delivery.Attempts - 1)
+		if backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+		if retryAfter > backoff {
+			backoff = retryAfter
+		}
+		delivery.NextAttemptAt = time.Now().Add(backoff)
+	}
+
+	return u.webhookRepo.UpdateDelivery(delivery)
+}
+
+// send
+// @description: 署名を付けてPOSTする。エラーの場合は応答のステータス（なければ0）とRetry-Afterも返す
+func (u *webhookUseCase) send(webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, time.Duration, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ImageGallery-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+webhookSignature(webhook.Secret, timestamp, body))
+
+	res, err := u.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(res.Body, webhookMaxErrorBytes))
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, 0, nil
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return res.StatusCode, retryAfter, fmt.Errorf("unexpected status %d: %s", res.StatusCode, strings.TrimSpace(string(snippet)))
+}
+
+// webhookSignature
+// @description: 受け取った側が検証できるよう、時刻と本文をつなげたものにHMAC-SHA256で署名する
+// （時刻を含めることで古い送信の使い回しを見分けられる）
+func webhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDelivery
+// @description: Webhookの形式に合わせて送信内容を作る
+func (u *webhookUseCase) newDelivery(webhook *domain.Webhook, event domain.WebhookEvent) (*domain.WebhookDelivery, error) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	link := event.URL
+	if strings.HasPrefix(link, "/") {
+		link = ""
+		if u.siteURL != "" {
+			link = u.siteURL + event.URL
+		}
+	}
+
+	var payload interface{}
+	switch webhook.Format {
+	case domain.WebhookFormatDiscord:
+		embed := map[string]interface{}{
+			"title":       truncateRunes(event.Title, discordMaxTitleLen),
+			"description": truncateRunes(event.Description, discordMaxDescriptionLen),
+			"color":       discordEmbedColor,
+			"timestamp":   event.OccurredAt.UTC().Format(time.RFC3339),
+		}
+		if link != "" {
+			embed["url"] = link
+		}
+		if event.Author != "" {
+			embed["author"] = map[string]string{"name": event.Author}
+		}
+		if event.ImageURL != "" {
+			embed["image"] = map[string]string{"url": event.ImageURL}
+		}
+		payload = map[string]interface{}{
+			"embeds":           []interface{}{embed},
+			"allowed_mentions": map[string]interface{}{"parse": []string{}},
+		}
+	default:
+		generic := map[string]interface{}{
+			"event":       event.Type,
+			"occurred_at": event.OccurredAt.UTC().Format(time.RFC3339),
+			"data":        event.Data,
+		}
+		if link != "" {
+			generic["url"] = link
+		}
+		payload = generic
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     event.Type,
+		Payload:   string(body),
+	}, nil
+}
+
+// setWebhookConfig
+// @description: 入力を確かめてWebhookの設定に反映する
+func setWebhookConfig(webhook *domain.Webhook, name, rawURL, format string, events []string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > webhookMaxNameLen {
+		return domain.ErrInvalidInput
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return domain.ErrInvalidInput
+	}
+
+	if format == "" {
+		format = domain.WebhookFormatGeneric
+	}
+	if format != domain.WebhookFormatDiscord && format != domain.WebhookFormatGeneric {
+		return domain.ErrInvalidInput
+	}
+
+	if len(events) == 0 {
+		return domain.ErrInvalidInput
+	}
+	for _, event := range events {
+		if !isWebhookEvent(event) {
+			return domain.ErrInvalidInput
+		}
+	}
+
+	webhook.Name = name
+	webhook.URL = rawURL
+	webhook.Format = format
+	webhook.Events = strings.Join(events, ",")
+	return nil
+}
+
+// isWebhookEvent
+// @description: 購読できる出来事かどうか
+func isWebhookEvent(event string) bool {
+	for _, e := range domain.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateRunes
+// @description: 文字数の上限を超える場合は末尾を省略する
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}